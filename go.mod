@@ -8,8 +8,11 @@ require (
 	github.com/coreos/go-oidc/v3 v3.12.0
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
 	github.com/pressly/goose/v3 v3.26.0
+	golang.org/x/net v0.47.0
 	golang.org/x/oauth2 v0.27.0
+	golang.org/x/text v0.31.0
 )
 
 require (
@@ -22,5 +25,4 @@ require (
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/crypto v0.45.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
-	golang.org/x/text v0.31.0 // indirect
 )
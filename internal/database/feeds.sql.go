@@ -14,7 +14,7 @@ import (
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (name, url, link, description, image_url, image_text, language, user_id)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, name, url, link, description, image_url, image_text, language, user_id, last_fetched_at, created_at, updated_at
+RETURNING id, name, url, link, description, image_url, image_text, language, user_id, last_fetched_at, created_at, updated_at, etag, last_modified, next_fetch_at, consecutive_failures, last_error, last_error_at, disabled, update_hint_seconds
 `
 
 type CreateFeedParams struct {
@@ -53,6 +53,14 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.LastFetchedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.UpdateHintSeconds,
 	)
 	return i, err
 }
@@ -60,7 +68,7 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 const createFeedFollows = `-- name: CreateFeedFollows :one
 INSERT INTO feed_follows (user_id, feed_id)
 VALUES ($1, $2)
-RETURNING id, user_id, feed_id, created_at, updated_at
+RETURNING id, user_id, feed_id, created_at, updated_at, custom_name, hide_from_home, sort_order
 `
 
 type CreateFeedFollowsParams struct {
@@ -77,25 +85,82 @@ func (q *Queries) CreateFeedFollows(ctx context.Context, arg CreateFeedFollowsPa
 		&i.FeedID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CustomName,
+		&i.HideFromHome,
+		&i.SortOrder,
 	)
 	return i, err
 }
 
-const deleteFeedFollows = `-- name: DeleteFeedFollows :exec
+const deleteFeedFollowByUrl = `-- name: DeleteFeedFollowByUrl :execrows
+DELETE FROM feed_follows ff
+USING feeds f
+WHERE ff.feed_id = f.id AND ff.user_id = $1 AND f.url = $2
+`
+
+type DeleteFeedFollowByUrlParams struct {
+	UserID int64  `json:"user_id"`
+	Url    string `json:"url"`
+}
+
+func (q *Queries) DeleteFeedFollowByUrl(ctx context.Context, arg DeleteFeedFollowByUrlParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollowByUrl, arg.UserID, arg.Url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFeedFollows = `-- name: DeleteFeedFollows :execrows
 DELETE FROM feed_follows
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteFeedFollowsParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteFeedFollows(ctx context.Context, arg DeleteFeedFollowsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollows, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const enableFeed = `-- name: EnableFeed :exec
+UPDATE feeds
+SET
+	disabled = FALSE,
+	consecutive_failures = 0,
+	next_fetch_at = NULL,
+	updated_at = NOW()
 WHERE id = $1
 `
 
-func (q *Queries) DeleteFeedFollows(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteFeedFollows, id)
+func (q *Queries) EnableFeed(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, enableFeed, id)
 	return err
 }
 
 const getAllFeedFollowsByEmail = `-- name: GetAllFeedFollowsByEmail :many
-SELECT f.id, f."name", f.url, f.link, f.description, f.created_at, ff.id AS feed_follow_id
+SELECT f.id, COALESCE(ff.custom_name, f.name) AS name, f.url, f.link, f.description, f.created_at, ff.id AS feed_follow_id,
+       f.last_error, f.last_error_at, f.consecutive_failures, f.disabled,
+       ff.custom_name, ff.hide_from_home, ff.sort_order,
+       pc.unread_count, pc.total_count, pc.newest_post_at
 FROM feed_follows ff
 INNER JOIN feeds f ON ff.feed_id = f.id
 INNER JOIN users u ON ff.user_id = u.id
+CROSS JOIN LATERAL (
+  SELECT COUNT(*) AS total_count,
+         COUNT(*) FILTER (WHERE ur.post_id IS NULL) AS unread_count,
+         MAX(p.published_at) AS newest_post_at
+  FROM posts p
+  LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+  WHERE p.feed_id = f.id
+    AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+) pc
 WHERE u.email = $1
 LIMIT $2
 OFFSET $3
@@ -108,13 +173,23 @@ type GetAllFeedFollowsByEmailParams struct {
 }
 
 type GetAllFeedFollowsByEmailRow struct {
-	ID           int64          `json:"id"`
-	Name         string         `json:"name"`
-	Url          string         `json:"url"`
-	Link         sql.NullString `json:"link"`
-	Description  sql.NullString `json:"description"`
-	CreatedAt    time.Time      `json:"created_at"`
-	FeedFollowID int64          `json:"feed_follow_id"`
+	ID                  int64          `json:"id"`
+	Name                string         `json:"name"`
+	Url                 string         `json:"url"`
+	Link                sql.NullString `json:"link"`
+	Description         sql.NullString `json:"description"`
+	CreatedAt           time.Time      `json:"created_at"`
+	FeedFollowID        int64          `json:"feed_follow_id"`
+	LastError           sql.NullString `json:"last_error"`
+	LastErrorAt         sql.NullTime   `json:"last_error_at"`
+	ConsecutiveFailures int32          `json:"consecutive_failures"`
+	Disabled            bool           `json:"disabled"`
+	CustomName          sql.NullString `json:"custom_name"`
+	HideFromHome        bool           `json:"hide_from_home"`
+	SortOrder           string         `json:"sort_order"`
+	UnreadCount         int64          `json:"unread_count"`
+	TotalCount          int64          `json:"total_count"`
+	NewestPostAt        sql.NullTime   `json:"newest_post_at"`
 }
 
 func (q *Queries) GetAllFeedFollowsByEmail(ctx context.Context, arg GetAllFeedFollowsByEmailParams) ([]GetAllFeedFollowsByEmailRow, error) {
@@ -134,6 +209,16 @@ func (q *Queries) GetAllFeedFollowsByEmail(ctx context.Context, arg GetAllFeedFo
 			&i.Description,
 			&i.CreatedAt,
 			&i.FeedFollowID,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.ConsecutiveFailures,
+			&i.Disabled,
+			&i.CustomName,
+			&i.HideFromHome,
+			&i.SortOrder,
+			&i.UnreadCount,
+			&i.TotalCount,
+			&i.NewestPostAt,
 		); err != nil {
 			return nil, err
 		}
@@ -190,8 +275,90 @@ func (q *Queries) GetAllFeeds(ctx context.Context, arg GetAllFeedsParams) ([]Get
 	return items, nil
 }
 
+const getDueFeeds = `-- name: GetDueFeeds :many
+SELECT id, name, url, etag, last_modified, consecutive_failures, update_hint_seconds
+FROM feeds
+WHERE NOT disabled AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+ORDER BY next_fetch_at ASC NULLS FIRST
+`
+
+type GetDueFeedsRow struct {
+	ID                  int64          `json:"id"`
+	Name                string         `json:"name"`
+	Url                 string         `json:"url"`
+	Etag                sql.NullString `json:"etag"`
+	LastModified        sql.NullString `json:"last_modified"`
+	ConsecutiveFailures int32          `json:"consecutive_failures"`
+	UpdateHintSeconds   int32          `json:"update_hint_seconds"`
+}
+
+func (q *Queries) GetDueFeeds(ctx context.Context) ([]GetDueFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDueFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDueFeedsRow
+	for rows.Next() {
+		var i GetDueFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.UpdateHintSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedById = `-- name: GetFeedById :one
+SELECT id, name, url, link, description, image_url, image_text, language, user_id, last_fetched_at, created_at, updated_at, etag, last_modified, next_fetch_at, consecutive_failures, last_error, last_error_at, disabled, update_hint_seconds
+FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeedById(ctx context.Context, id int64) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedById, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Link,
+		&i.Description,
+		&i.ImageUrl,
+		&i.ImageText,
+		&i.Language,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.UpdateHintSeconds,
+	)
+	return i, err
+}
+
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, name, url, link, description, image_url, image_text, language, user_id, last_fetched_at, created_at, updated_at
+SELECT id, name, url, link, description, image_url, image_text, language, user_id, last_fetched_at, created_at, updated_at, etag, last_modified, next_fetch_at, consecutive_failures, last_error, last_error_at, disabled, update_hint_seconds
 FROM feeds
 WHERE url = $1
 `
@@ -212,6 +379,40 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.LastFetchedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastErrorAt,
+		&i.Disabled,
+		&i.UpdateHintSeconds,
+	)
+	return i, err
+}
+
+const getFeedFollowForUser = `-- name: GetFeedFollowForUser :one
+SELECT id, user_id, feed_id, created_at, updated_at, custom_name, hide_from_home, sort_order FROM feed_follows
+WHERE id = $1 AND user_id = $2
+`
+
+type GetFeedFollowForUserParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetFeedFollowForUser(ctx context.Context, arg GetFeedFollowForUserParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowForUser, arg.ID, arg.UserID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomName,
+		&i.HideFromHome,
+		&i.SortOrder,
 	)
 	return i, err
 }
@@ -236,15 +437,41 @@ func (q *Queries) GetFeedFollows(ctx context.Context, arg GetFeedFollowsParams)
 }
 
 const getFeedFollowsFromUser = `-- name: GetFeedFollowsFromUser :many
-SELECT id, user_id, feed_id
-FROM feed_follows
-WHERE user_id = $1
+SELECT ff.id, ff.user_id, ff.feed_id, COALESCE(ff.custom_name, f.name) AS name, f.url, f.last_fetched_at,
+       f.last_error, f.last_error_at, f.consecutive_failures, f.disabled,
+       ff.custom_name, ff.hide_from_home, ff.sort_order,
+       pc.unread_count, pc.total_count, pc.newest_post_at
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+CROSS JOIN LATERAL (
+  SELECT COUNT(*) AS total_count,
+         COUNT(*) FILTER (WHERE ur.post_id IS NULL) AS unread_count,
+         MAX(p.published_at) AS newest_post_at
+  FROM posts p
+  LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+  WHERE p.feed_id = f.id
+    AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+) pc
+WHERE ff.user_id = $1
 `
 
 type GetFeedFollowsFromUserRow struct {
-	ID     int64 `json:"id"`
-	UserID int64 `json:"user_id"`
-	FeedID int64 `json:"feed_id"`
+	ID                  int64          `json:"id"`
+	UserID              int64          `json:"user_id"`
+	FeedID              int64          `json:"feed_id"`
+	Name                string         `json:"name"`
+	Url                 string         `json:"url"`
+	LastFetchedAt       sql.NullTime   `json:"last_fetched_at"`
+	LastError           sql.NullString `json:"last_error"`
+	LastErrorAt         sql.NullTime   `json:"last_error_at"`
+	ConsecutiveFailures int32          `json:"consecutive_failures"`
+	Disabled            bool           `json:"disabled"`
+	CustomName          sql.NullString `json:"custom_name"`
+	HideFromHome        bool           `json:"hide_from_home"`
+	SortOrder           string         `json:"sort_order"`
+	UnreadCount         int64          `json:"unread_count"`
+	TotalCount          int64          `json:"total_count"`
+	NewestPostAt        sql.NullTime   `json:"newest_post_at"`
 }
 
 func (q *Queries) GetFeedFollowsFromUser(ctx context.Context, userID int64) ([]GetFeedFollowsFromUserRow, error) {
@@ -256,7 +483,24 @@ func (q *Queries) GetFeedFollowsFromUser(ctx context.Context, userID int64) ([]G
 	var items []GetFeedFollowsFromUserRow
 	for rows.Next() {
 		var i GetFeedFollowsFromUserRow
-		if err := rows.Scan(&i.ID, &i.UserID, &i.FeedID); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.FeedID,
+			&i.Name,
+			&i.Url,
+			&i.LastFetchedAt,
+			&i.LastError,
+			&i.LastErrorAt,
+			&i.ConsecutiveFailures,
+			&i.Disabled,
+			&i.CustomName,
+			&i.HideFromHome,
+			&i.SortOrder,
+			&i.UnreadCount,
+			&i.TotalCount,
+			&i.NewestPostAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -270,29 +514,40 @@ func (q *Queries) GetFeedFollowsFromUser(ctx context.Context, userID int64) ([]G
 	return items, nil
 }
 
-const getNextFeedsToFetch = `-- name: GetNextFeedsToFetch :many
-SELECT id, name, url
-FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT $1
+const getSubscriptionsByUser = `-- name: GetSubscriptionsByUser :many
+SELECT ff.id, ff.feed_id, COALESCE(ff.custom_name, f.name) AS name, f.url, f.link, ff.created_at
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+WHERE ff.user_id = $1
+ORDER BY name
 `
 
-type GetNextFeedsToFetchRow struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
-	Url  string `json:"url"`
+type GetSubscriptionsByUserRow struct {
+	ID        int64          `json:"id"`
+	FeedID    int64          `json:"feed_id"`
+	Name      string         `json:"name"`
+	Url       string         `json:"url"`
+	Link      sql.NullString `json:"link"`
+	CreatedAt time.Time      `json:"created_at"`
 }
 
-func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]GetNextFeedsToFetchRow, error) {
-	rows, err := q.db.QueryContext(ctx, getNextFeedsToFetch, limit)
+func (q *Queries) GetSubscriptionsByUser(ctx context.Context, userID int64) ([]GetSubscriptionsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSubscriptionsByUser, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetNextFeedsToFetchRow
+	var items []GetSubscriptionsByUserRow
 	for rows.Next() {
-		var i GetNextFeedsToFetchRow
-		if err := rows.Scan(&i.ID, &i.Name, &i.Url); err != nil {
+		var i GetSubscriptionsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Name,
+			&i.Url,
+			&i.Link,
+			&i.CreatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -306,15 +561,100 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]GetNe
 	return items, nil
 }
 
+const markFeedFailed = `-- name: MarkFeedFailed :exec
+UPDATE feeds
+SET
+	consecutive_failures = consecutive_failures + 1,
+	next_fetch_at = $1,
+	last_error = $2,
+	last_error_at = NOW(),
+	disabled = consecutive_failures + 1 >= $3::int,
+	updated_at = NOW()
+WHERE id = $4
+`
+
+type MarkFeedFailedParams struct {
+	NextFetchAt sql.NullTime   `json:"next_fetch_at"`
+	LastError   sql.NullString `json:"last_error"`
+	MaxFailures int32          `json:"max_failures"`
+	ID          int64          `json:"id"`
+}
+
+func (q *Queries) MarkFeedFailed(ctx context.Context, arg MarkFeedFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFailed,
+		arg.NextFetchAt,
+		arg.LastError,
+		arg.MaxFailures,
+		arg.ID,
+	)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds
 SET
 	last_fetched_at = NOW(),
+	etag = $2,
+	last_modified = $3,
+	next_fetch_at = $4,
+	update_hint_seconds = $5,
+	consecutive_failures = 0,
 	updated_at = NOW()
 WHERE id = $1
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+type MarkFeedFetchedParams struct {
+	ID                int64          `json:"id"`
+	Etag              sql.NullString `json:"etag"`
+	LastModified      sql.NullString `json:"last_modified"`
+	NextFetchAt       sql.NullTime   `json:"next_fetch_at"`
+	UpdateHintSeconds int32          `json:"update_hint_seconds"`
+}
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched,
+		arg.ID,
+		arg.Etag,
+		arg.LastModified,
+		arg.NextFetchAt,
+		arg.UpdateHintSeconds,
+	)
 	return err
 }
+
+const updateFeedFollowSettings = `-- name: UpdateFeedFollowSettings :one
+UPDATE feed_follows
+SET custom_name = $1, hide_from_home = $2, sort_order = $3, updated_at = NOW()
+WHERE id = $4 AND user_id = $5
+RETURNING id, user_id, feed_id, created_at, updated_at, custom_name, hide_from_home, sort_order
+`
+
+type UpdateFeedFollowSettingsParams struct {
+	CustomName   sql.NullString `json:"custom_name"`
+	HideFromHome bool           `json:"hide_from_home"`
+	SortOrder    string         `json:"sort_order"`
+	ID           int64          `json:"id"`
+	UserID       int64          `json:"user_id"`
+}
+
+func (q *Queries) UpdateFeedFollowSettings(ctx context.Context, arg UpdateFeedFollowSettingsParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedFollowSettings,
+		arg.CustomName,
+		arg.HideFromHome,
+		arg.SortOrder,
+		arg.ID,
+		arg.UserID,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CustomName,
+		&i.HideFromHome,
+		&i.SortOrder,
+	)
+	return i, err
+}
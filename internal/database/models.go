@@ -9,40 +9,133 @@ import (
 	"time"
 )
 
+type ApiToken struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Name       string       `json:"name"`
+	TokenHash  string       `json:"token_hash"`
+	Scope      string       `json:"scope"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+}
+
 type Feed struct {
-	ID            int64          `json:"id"`
-	Name          string         `json:"name"`
-	Url           string         `json:"url"`
-	Link          sql.NullString `json:"link"`
-	Description   sql.NullString `json:"description"`
-	ImageUrl      sql.NullString `json:"image_url"`
-	ImageText     sql.NullString `json:"image_text"`
-	Language      sql.NullString `json:"language"`
-	UserID        int64          `json:"user_id"`
-	LastFetchedAt sql.NullTime   `json:"last_fetched_at"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
+	ID                  int64          `json:"id"`
+	Name                string         `json:"name"`
+	Url                 string         `json:"url"`
+	Link                sql.NullString `json:"link"`
+	Description         sql.NullString `json:"description"`
+	ImageUrl            sql.NullString `json:"image_url"`
+	ImageText           sql.NullString `json:"image_text"`
+	Language            sql.NullString `json:"language"`
+	UserID              int64          `json:"user_id"`
+	LastFetchedAt       sql.NullTime   `json:"last_fetched_at"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	Etag                sql.NullString `json:"etag"`
+	LastModified        sql.NullString `json:"last_modified"`
+	NextFetchAt         sql.NullTime   `json:"next_fetch_at"`
+	ConsecutiveFailures int32          `json:"consecutive_failures"`
+	LastError           sql.NullString `json:"last_error"`
+	LastErrorAt         sql.NullTime   `json:"last_error_at"`
+	Disabled            bool           `json:"disabled"`
+	UpdateHintSeconds   int32          `json:"update_hint_seconds"`
 }
 
 type FeedFollow struct {
+	ID           int64          `json:"id"`
+	UserID       int64          `json:"user_id"`
+	FeedID       int64          `json:"feed_id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	CustomName   sql.NullString `json:"custom_name"`
+	HideFromHome bool           `json:"hide_from_home"`
+	SortOrder    string         `json:"sort_order"`
+}
+
+type FeedFollowFolder struct {
+	ID           int64     `json:"id"`
+	FeedFollowID int64     `json:"feed_follow_id"`
+	FolderID     int64     `json:"folder_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type FilterRule struct {
+	ID        int64         `json:"id"`
+	UserID    int64         `json:"user_id"`
+	FeedID    sql.NullInt64 `json:"feed_id"`
+	Field     string        `json:"field"`
+	MatchType string        `json:"match_type"`
+	Pattern   string        `json:"pattern"`
+	Action    string        `json:"action"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type Folder struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"user_id"`
-	FeedID    int64     `json:"feed_id"`
+	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type OpmlImport struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Status     string       `json:"status"`
+	Total      int32        `json:"total"`
+	CreatedAt  time.Time    `json:"created_at"`
+	FinishedAt sql.NullTime `json:"finished_at"`
+}
+
+type OpmlImportItem struct {
+	ID        int64          `json:"id"`
+	ImportID  int64          `json:"import_id"`
+	Url       string         `json:"url"`
+	Title     string         `json:"title"`
+	Status    string         `json:"status"`
+	Message   sql.NullString `json:"message"`
+	FeedID    sql.NullInt64  `json:"feed_id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
 type Post struct {
-	ID          int64          `json:"id"`
-	Title       string         `json:"title"`
-	Url         string         `json:"url"`
-	Description sql.NullString `json:"description"`
-	Content     sql.NullString `json:"content"`
-	Author      string         `json:"author"`
-	FeedID      int64          `json:"feed_id"`
-	PublishedAt time.Time      `json:"published_at"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
+	ID           int64          `json:"id"`
+	Title        string         `json:"title"`
+	Url          string         `json:"url"`
+	Description  sql.NullString `json:"description"`
+	Content      sql.NullString `json:"content"`
+	Author       string         `json:"author"`
+	FeedID       int64          `json:"feed_id"`
+	PublishedAt  time.Time      `json:"published_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	SearchVector interface{}    `json:"-"`
+}
+
+type PostCategory struct {
+	ID     int64  `json:"id"`
+	PostID int64  `json:"post_id"`
+	Name   string `json:"name"`
+}
+
+type PostEnclosure struct {
+	ID           int64          `json:"id"`
+	PostID       int64          `json:"post_id"`
+	Url          string         `json:"url"`
+	MimeType     string         `json:"mime_type"`
+	Length       int64          `json:"length"`
+	Duration     int32          `json:"duration"`
+	ThumbnailUrl sql.NullString `json:"thumbnail_url"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type PostMedium struct {
+	PostID   int64  `json:"post_id"`
+	Url      string `json:"url"`
+	MimeType string `json:"mime_type"`
 }
 
 type Session struct {
@@ -51,6 +144,7 @@ type Session struct {
 	UserID    int64     `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
+	CsrfToken string    `json:"csrf_token"`
 }
 
 type User struct {
@@ -68,3 +162,18 @@ type UsersBookmark struct {
 	UserID    int64     `json:"user_id"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type UsersHiddenPost struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	PostID    int64     `json:"post_id"`
+	RuleID    int64     `json:"rule_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UsersRead struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
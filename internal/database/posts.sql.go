@@ -9,11 +9,18 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
-const bookmarkPost = `-- name: BookmarkPost :exec
+const bookmarkPost = `-- name: BookmarkPost :execrows
 INSERT INTO users_bookmarks (user_id, post_id)
-VALUES ($1, $2)
+SELECT ff.user_id, p.id
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = $1 AND p.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET created_at = users_bookmarks.created_at
 `
 
 type BookmarkPostParams struct {
@@ -21,14 +28,17 @@ type BookmarkPostParams struct {
 	PostID int64 `json:"post_id"`
 }
 
-func (q *Queries) BookmarkPost(ctx context.Context, arg BookmarkPostParams) error {
-	_, err := q.db.ExecContext(ctx, bookmarkPost, arg.UserID, arg.PostID)
-	return err
+func (q *Queries) BookmarkPost(ctx context.Context, arg BookmarkPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, bookmarkPost, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (title, url, description, author, feed_id, published_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO posts (title, url, description, content, author, feed_id, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING id, title, url, description, content, author, feed_id, published_at, created_at, updated_at
 `
 
@@ -36,21 +46,36 @@ type CreatePostParams struct {
 	Title       string         `json:"title"`
 	Url         string         `json:"url"`
 	Description sql.NullString `json:"description"`
+	Content     sql.NullString `json:"content"`
 	Author      string         `json:"author"`
 	FeedID      int64          `json:"feed_id"`
 	PublishedAt time.Time      `json:"published_at"`
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
+type CreatePostRow struct {
+	ID          int64          `json:"id"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	Content     sql.NullString `json:"content"`
+	Author      string         `json:"author"`
+	FeedID      int64          `json:"feed_id"`
+	PublishedAt time.Time      `json:"published_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (CreatePostRow, error) {
 	row := q.db.QueryRowContext(ctx, createPost,
 		arg.Title,
 		arg.Url,
 		arg.Description,
+		arg.Content,
 		arg.Author,
 		arg.FeedID,
 		arg.PublishedAt,
 	)
-	var i Post
+	var i CreatePostRow
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
@@ -67,10 +92,13 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 }
 
 const getBookmarkedPostsByDate = `-- name: GetBookmarkedPostsByDate :many
-SELECT p.id, p.title, p.url, p.published_at, f.name
+SELECT p.id, p.title, p.url, p.published_at, COALESCE(ff.custom_name, f.name) AS name,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM users_bookmarks ub
 INNER JOIN posts p ON p.id = ub.post_id
 INNER JOIN feeds f ON p.feed_id = f.id
+LEFT JOIN feed_follows ff ON ff.feed_id = f.id AND ff.user_id = ub.user_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
 WHERE ub.user_id = $1
 ORDER BY ub.created_at DESC
 LIMIT $2
@@ -84,11 +112,13 @@ type GetBookmarkedPostsByDateParams struct {
 }
 
 type GetBookmarkedPostsByDateRow struct {
-	ID          int64     `json:"id"`
-	Title       string    `json:"title"`
-	Url         string    `json:"url"`
-	PublishedAt time.Time `json:"published_at"`
-	Name        string    `json:"name"`
+	ID            int64          `json:"id"`
+	Title         string         `json:"title"`
+	Url           string         `json:"url"`
+	PublishedAt   time.Time      `json:"published_at"`
+	Name          string         `json:"name"`
+	EnclosureUrl  sql.NullString `json:"enclosure_url"`
+	EnclosureType sql.NullString `json:"enclosure_type"`
 }
 
 func (q *Queries) GetBookmarkedPostsByDate(ctx context.Context, arg GetBookmarkedPostsByDateParams) ([]GetBookmarkedPostsByDateRow, error) {
@@ -106,6 +136,8 @@ func (q *Queries) GetBookmarkedPostsByDate(ctx context.Context, arg GetBookmarke
 			&i.Url,
 			&i.PublishedAt,
 			&i.Name,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
 		); err != nil {
 			return nil, err
 		}
@@ -121,9 +153,11 @@ func (q *Queries) GetBookmarkedPostsByDate(ctx context.Context, arg GetBookmarke
 }
 
 const getBookmarkedPostsByPublished = `-- name: GetBookmarkedPostsByPublished :many
-SELECT p.id, p.title, p.url, p.published_at
+SELECT p.id, p.title, p.url, p.published_at,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM users_bookmarks ub
 INNER JOIN posts p ON p.id = ub.post_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
 WHERE ub.user_id = $1
 ORDER BY p.published_at DESC
 LIMIT $2
@@ -137,10 +171,12 @@ type GetBookmarkedPostsByPublishedParams struct {
 }
 
 type GetBookmarkedPostsByPublishedRow struct {
-	ID          int64     `json:"id"`
-	Title       string    `json:"title"`
-	Url         string    `json:"url"`
-	PublishedAt time.Time `json:"published_at"`
+	ID            int64          `json:"id"`
+	Title         string         `json:"title"`
+	Url           string         `json:"url"`
+	PublishedAt   time.Time      `json:"published_at"`
+	EnclosureUrl  sql.NullString `json:"enclosure_url"`
+	EnclosureType sql.NullString `json:"enclosure_type"`
 }
 
 func (q *Queries) GetBookmarkedPostsByPublished(ctx context.Context, arg GetBookmarkedPostsByPublishedParams) ([]GetBookmarkedPostsByPublishedRow, error) {
@@ -157,6 +193,8 @@ func (q *Queries) GetBookmarkedPostsByPublished(ctx context.Context, arg GetBook
 			&i.Title,
 			&i.Url,
 			&i.PublishedAt,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
 		); err != nil {
 			return nil, err
 		}
@@ -171,6 +209,143 @@ func (q *Queries) GetBookmarkedPostsByPublished(ctx context.Context, arg GetBook
 	return items, nil
 }
 
+const getNewerPost = `-- name: GetNewerPost :one
+SELECT p.id, p.title
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id AND ff.user_id = $1
+WHERE (p.published_at > $2
+       OR (p.published_at = $2 AND p.id > $3))
+  AND ($4::bigint IS NULL OR p.feed_id = $4::bigint)
+  AND ($5::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = $5::bigint
+  ))
+  AND ($4::bigint IS NOT NULL OR $5::bigint IS NOT NULL OR NOT ff.hide_from_home)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY p.published_at ASC, p.id ASC
+LIMIT 1
+`
+
+type GetNewerPostParams struct {
+	UserID      int64         `json:"user_id"`
+	PublishedAt time.Time     `json:"published_at"`
+	ID          int64         `json:"id"`
+	FeedID      sql.NullInt64 `json:"feed_id"`
+	FolderID    sql.NullInt64 `json:"folder_id"`
+}
+
+type GetNewerPostRow struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+func (q *Queries) GetNewerPost(ctx context.Context, arg GetNewerPostParams) (GetNewerPostRow, error) {
+	row := q.db.QueryRowContext(ctx, getNewerPost,
+		arg.UserID,
+		arg.PublishedAt,
+		arg.ID,
+		arg.FeedID,
+		arg.FolderID,
+	)
+	var i GetNewerPostRow
+	err := row.Scan(&i.ID, &i.Title)
+	return i, err
+}
+
+const getOlderPost = `-- name: GetOlderPost :one
+SELECT p.id, p.title
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id AND ff.user_id = $1
+WHERE (p.published_at < $2
+       OR (p.published_at = $2 AND p.id < $3))
+  AND ($4::bigint IS NULL OR p.feed_id = $4::bigint)
+  AND ($5::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = $5::bigint
+  ))
+  AND ($4::bigint IS NOT NULL OR $5::bigint IS NOT NULL OR NOT ff.hide_from_home)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT 1
+`
+
+type GetOlderPostParams struct {
+	UserID      int64         `json:"user_id"`
+	PublishedAt time.Time     `json:"published_at"`
+	ID          int64         `json:"id"`
+	FeedID      sql.NullInt64 `json:"feed_id"`
+	FolderID    sql.NullInt64 `json:"folder_id"`
+}
+
+type GetOlderPostRow struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+func (q *Queries) GetOlderPost(ctx context.Context, arg GetOlderPostParams) (GetOlderPostRow, error) {
+	row := q.db.QueryRowContext(ctx, getOlderPost,
+		arg.UserID,
+		arg.PublishedAt,
+		arg.ID,
+		arg.FeedID,
+		arg.FolderID,
+	)
+	var i GetOlderPostRow
+	err := row.Scan(&i.ID, &i.Title)
+	return i, err
+}
+
+const getPostForUser = `-- name: GetPostForUser :one
+SELECT p.id, p.title, p.url, p.description, p.content, p.author, p.published_at,
+       f.id AS feed_id, COALESCE(ff.custom_name, f.name) AS feed_name,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_read
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = f.id AND ff.user_id = $1
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+WHERE p.id = $2
+`
+
+type GetPostForUserParams struct {
+	UserID int64 `json:"user_id"`
+	ID     int64 `json:"id"`
+}
+
+type GetPostForUserRow struct {
+	ID           int64          `json:"id"`
+	Title        string         `json:"title"`
+	Url          string         `json:"url"`
+	Description  sql.NullString `json:"description"`
+	Content      sql.NullString `json:"content"`
+	Author       string         `json:"author"`
+	PublishedAt  time.Time      `json:"published_at"`
+	FeedID       int64          `json:"feed_id"`
+	FeedName     string         `json:"feed_name"`
+	IsBookmarked int32          `json:"is_bookmarked"`
+	IsRead       int32          `json:"is_read"`
+}
+
+func (q *Queries) GetPostForUser(ctx context.Context, arg GetPostForUserParams) (GetPostForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUser, arg.UserID, arg.ID)
+	var i GetPostForUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.Content,
+		&i.Author,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.FeedName,
+		&i.IsBookmarked,
+		&i.IsRead,
+	)
+	return i, err
+}
+
 const getPostsByUser = `-- name: GetPostsByUser :many
 SELECT p.id, f.name, p.title, p.author, p.url, p.published_at
 FROM feed_follows ff
@@ -290,15 +465,20 @@ func (q *Queries) GetPostsByUserAndFeed(ctx context.Context, arg GetPostsByUserA
 }
 
 const getPostsByUserAndFeedWithBookmarks = `-- name: GetPostsByUserAndFeedWithBookmarks :many
-SELECT p.id, p.title, f.name, p.url, p.published_at,
-       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked
+SELECT p.id, p.title, COALESCE(ff.custom_name, f.name) AS name, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END as is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM feed_follows ff
 INNER JOIN feeds f ON ff.feed_id = f.id
 INNER JOIN posts p ON p.feed_id = f.id
 INNER JOIN users u ON ff.user_id = u.id
 LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = u.id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = u.id
+LEFT JOIN post_media pe ON pe.post_id = p.id
 WHERE u.email = $1 AND f.id = $2
-ORDER BY p.published_at DESC
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = u.id AND uh.post_id = p.id)
+ORDER BY CASE WHEN ff.sort_order = 'oldest' THEN p.published_at END ASC, p.published_at DESC
 LIMIT $3
 OFFSET $4
 `
@@ -311,12 +491,15 @@ type GetPostsByUserAndFeedWithBookmarksParams struct {
 }
 
 type GetPostsByUserAndFeedWithBookmarksRow struct {
-	ID           int64     `json:"id"`
-	Title        string    `json:"title"`
-	Name         string    `json:"name"`
-	Url          string    `json:"url"`
-	PublishedAt  time.Time `json:"published_at"`
-	IsBookmarked int32     `json:"is_bookmarked"`
+	ID            int64          `json:"id"`
+	Title         string         `json:"title"`
+	Name          string         `json:"name"`
+	Url           string         `json:"url"`
+	PublishedAt   time.Time      `json:"published_at"`
+	IsBookmarked  int32          `json:"is_bookmarked"`
+	IsRead        int32          `json:"is_read"`
+	EnclosureUrl  sql.NullString `json:"enclosure_url"`
+	EnclosureType sql.NullString `json:"enclosure_type"`
 }
 
 func (q *Queries) GetPostsByUserAndFeedWithBookmarks(ctx context.Context, arg GetPostsByUserAndFeedWithBookmarksParams) ([]GetPostsByUserAndFeedWithBookmarksRow, error) {
@@ -340,6 +523,9 @@ func (q *Queries) GetPostsByUserAndFeedWithBookmarks(ctx context.Context, arg Ge
 			&i.Url,
 			&i.PublishedAt,
 			&i.IsBookmarked,
+			&i.IsRead,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
 		); err != nil {
 			return nil, err
 		}
@@ -355,38 +541,54 @@ func (q *Queries) GetPostsByUserAndFeedWithBookmarks(ctx context.Context, arg Ge
 }
 
 const getPostsByUserWithBookmarks = `-- name: GetPostsByUserWithBookmarks :many
-SELECT p.id, f.id as feed_id, f.name, p.title, p.author, p.url, p.published_at,
-       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked
+SELECT p.id, f.id as feed_id, COALESCE(ff.custom_name, f.name) AS name, p.title, p.author, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END as is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM feed_follows ff
 INNER JOIN users u ON ff.user_id = u.id
 INNER JOIN feeds f ON ff.feed_id = f.id
 INNER JOIN posts p ON p.feed_id = f.id
 LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = u.id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = u.id
+LEFT JOIN post_media pe ON pe.post_id = p.id
 WHERE u.email = $1
+  AND (NOT $2::boolean OR ur.post_id IS NULL)
+  AND NOT ff.hide_from_home
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = u.id AND uh.post_id = p.id)
 ORDER BY p.published_at DESC
-LIMIT $2
-OFFSET $3
+LIMIT $3
+OFFSET $4
 `
 
 type GetPostsByUserWithBookmarksParams struct {
-	Email  string `json:"email"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Email      string `json:"email"`
+	UnreadOnly bool   `json:"unread_only"`
+	PageLimit  int32  `json:"page_limit"`
+	PageOffset int32  `json:"page_offset"`
 }
 
 type GetPostsByUserWithBookmarksRow struct {
-	ID           int64     `json:"id"`
-	FeedID       int64     `json:"feed_id"`
-	Name         string    `json:"name"`
-	Title        string    `json:"title"`
-	Author       string    `json:"author"`
-	Url          string    `json:"url"`
-	PublishedAt  time.Time `json:"published_at"`
-	IsBookmarked int32     `json:"is_bookmarked"`
+	ID            int64          `json:"id"`
+	FeedID        int64          `json:"feed_id"`
+	Name          string         `json:"name"`
+	Title         string         `json:"title"`
+	Author        string         `json:"author"`
+	Url           string         `json:"url"`
+	PublishedAt   time.Time      `json:"published_at"`
+	IsBookmarked  int32          `json:"is_bookmarked"`
+	IsRead        int32          `json:"is_read"`
+	EnclosureUrl  sql.NullString `json:"enclosure_url"`
+	EnclosureType sql.NullString `json:"enclosure_type"`
 }
 
 func (q *Queries) GetPostsByUserWithBookmarks(ctx context.Context, arg GetPostsByUserWithBookmarksParams) ([]GetPostsByUserWithBookmarksRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsByUserWithBookmarks, arg.Email, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, getPostsByUserWithBookmarks,
+		arg.Email,
+		arg.UnreadOnly,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -403,6 +605,9 @@ func (q *Queries) GetPostsByUserWithBookmarks(ctx context.Context, arg GetPostsB
 			&i.Url,
 			&i.PublishedAt,
 			&i.IsBookmarked,
+			&i.IsRead,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
 		); err != nil {
 			return nil, err
 		}
@@ -417,6 +622,372 @@ func (q *Queries) GetPostsByUserWithBookmarks(ctx context.Context, arg GetPostsB
 	return items, nil
 }
 
+const getRecentPostDates = `-- name: GetRecentPostDates :many
+SELECT published_at
+FROM posts
+WHERE feed_id = $1
+ORDER BY published_at DESC
+LIMIT $2
+`
+
+type GetRecentPostDatesParams struct {
+	FeedID int64 `json:"feed_id"`
+	Limit  int32 `json:"limit"`
+}
+
+func (q *Queries) GetRecentPostDates(ctx context.Context, arg GetRecentPostDatesParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPostDates, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var published_at time.Time
+		if err := rows.Scan(&published_at); err != nil {
+			return nil, err
+		}
+		items = append(items, published_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStreamItemIDs = `-- name: GetStreamItemIDs :many
+SELECT p.id, p.published_at
+FROM feed_follows ff
+INNER JOIN posts p ON p.feed_id = ff.feed_id
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+WHERE ff.user_id = $1
+  AND ($2::bigint IS NULL OR ff.feed_id = $2::bigint)
+  AND ($3::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = $3::bigint
+  ))
+  AND ($4::boolean IS NULL OR (ur.post_id IS NOT NULL) = $4::boolean)
+  AND (NOT $5::boolean OR ub.post_id IS NOT NULL)
+  AND ($6::timestamptz IS NULL OR p.created_at >= $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR p.created_at < $7::timestamptz)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY CASE WHEN $8::boolean THEN p.published_at END ASC, p.published_at DESC, p.id DESC
+LIMIT $9
+OFFSET $10
+`
+
+type GetStreamItemIDsParams struct {
+	UserID        int64         `json:"user_id"`
+	FeedID        sql.NullInt64 `json:"feed_id"`
+	FolderID      sql.NullInt64 `json:"folder_id"`
+	IsRead        sql.NullBool  `json:"is_read"`
+	StarredOnly   bool          `json:"starred_only"`
+	CrawledAfter  sql.NullTime  `json:"crawled_after"`
+	CrawledBefore sql.NullTime  `json:"crawled_before"`
+	OldestFirst   bool          `json:"oldest_first"`
+	PageLimit     int32         `json:"page_limit"`
+	PageOffset    int32         `json:"page_offset"`
+}
+
+type GetStreamItemIDsRow struct {
+	ID          int64     `json:"id"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func (q *Queries) GetStreamItemIDs(ctx context.Context, arg GetStreamItemIDsParams) ([]GetStreamItemIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStreamItemIDs,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		arg.IsRead,
+		arg.StarredOnly,
+		arg.CrawledAfter,
+		arg.CrawledBefore,
+		arg.OldestFirst,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStreamItemIDsRow
+	for rows.Next() {
+		var i GetStreamItemIDsRow
+		if err := rows.Scan(&i.ID, &i.PublishedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStreamItems = `-- name: GetStreamItems :many
+SELECT p.id, p.title, p.url, p.description, p.content, p.author, p.published_at, p.created_at,
+       f.id AS feed_id, ff.id AS feed_follow_id, COALESCE(ff.custom_name, f.name) AS feed_name, f.url AS feed_url, f.link AS feed_link,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+INNER JOIN posts p ON p.feed_id = f.id
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
+WHERE ff.user_id = $1
+  AND p.id = ANY($2::bigint[])
+`
+
+type GetStreamItemsParams struct {
+	UserID int64   `json:"user_id"`
+	Ids    []int64 `json:"ids"`
+}
+
+type GetStreamItemsRow struct {
+	ID            int64          `json:"id"`
+	Title         string         `json:"title"`
+	Url           string         `json:"url"`
+	Description   sql.NullString `json:"description"`
+	Content       sql.NullString `json:"content"`
+	Author        string         `json:"author"`
+	PublishedAt   time.Time      `json:"published_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	FeedID        int64          `json:"feed_id"`
+	FeedFollowID  int64          `json:"feed_follow_id"`
+	FeedName      string         `json:"feed_name"`
+	FeedUrl       string         `json:"feed_url"`
+	FeedLink      sql.NullString `json:"feed_link"`
+	IsBookmarked  int32          `json:"is_bookmarked"`
+	IsRead        int32          `json:"is_read"`
+	EnclosureUrl  sql.NullString `json:"enclosure_url"`
+	EnclosureType sql.NullString `json:"enclosure_type"`
+}
+
+func (q *Queries) GetStreamItems(ctx context.Context, arg GetStreamItemsParams) ([]GetStreamItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStreamItems, arg.UserID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStreamItemsRow
+	for rows.Next() {
+		var i GetStreamItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.Content,
+			&i.Author,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedID,
+			&i.FeedFollowID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedLink,
+			&i.IsBookmarked,
+			&i.IsRead,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimelinePosts = `-- name: GetTimelinePosts :many
+SELECT p.id, f.id as feed_id, COALESCE(ff.custom_name, f.name) AS name, p.title, p.author, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END as is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+INNER JOIN posts p ON p.feed_id = f.id
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
+WHERE ff.user_id = $1
+  AND ($2::bigint IS NULL OR f.id = $2::bigint)
+  AND ($3::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = $3::bigint
+  ))
+  AND ($2::bigint IS NOT NULL OR $3::bigint IS NOT NULL OR NOT ff.hide_from_home)
+  AND (NOT $4::boolean OR ur.post_id IS NULL)
+  AND (NOT $5::boolean OR ub.post_id IS NOT NULL)
+  AND ($6::timestamptz IS NULL OR p.published_at >= $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR p.published_at < $7::timestamptz)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY CASE WHEN $8::boolean THEN p.published_at END ASC, p.published_at DESC, p.id DESC
+LIMIT $9
+OFFSET $10
+`
+
+type GetTimelinePostsParams struct {
+	UserID          int64         `json:"user_id"`
+	FeedID          sql.NullInt64 `json:"feed_id"`
+	FolderID        sql.NullInt64 `json:"folder_id"`
+	UnreadOnly      bool          `json:"unread_only"`
+	BookmarkedOnly  bool          `json:"bookmarked_only"`
+	PublishedAfter  sql.NullTime  `json:"published_after"`
+	PublishedBefore sql.NullTime  `json:"published_before"`
+	OldestFirst     bool          `json:"oldest_first"`
+	PageLimit       int32         `json:"page_limit"`
+	PageOffset      int32         `json:"page_offset"`
+}
+
+type GetTimelinePostsRow struct {
+	ID            int64          `json:"id"`
+	FeedID        int64          `json:"feed_id"`
+	Name          string         `json:"name"`
+	Title         string         `json:"title"`
+	Author        string         `json:"author"`
+	Url           string         `json:"url"`
+	PublishedAt   time.Time      `json:"published_at"`
+	IsBookmarked  int32          `json:"is_bookmarked"`
+	IsRead        int32          `json:"is_read"`
+	EnclosureUrl  sql.NullString `json:"enclosure_url"`
+	EnclosureType sql.NullString `json:"enclosure_type"`
+}
+
+func (q *Queries) GetTimelinePosts(ctx context.Context, arg GetTimelinePostsParams) ([]GetTimelinePostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTimelinePosts,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		arg.UnreadOnly,
+		arg.BookmarkedOnly,
+		arg.PublishedAfter,
+		arg.PublishedBefore,
+		arg.OldestFirst,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTimelinePostsRow
+	for rows.Next() {
+		var i GetTimelinePostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Name,
+			&i.Title,
+			&i.Author,
+			&i.Url,
+			&i.PublishedAt,
+			&i.IsBookmarked,
+			&i.IsRead,
+			&i.EnclosureUrl,
+			&i.EnclosureType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPostRead = `-- name: MarkPostRead :execrows
+INSERT INTO users_reads (user_id, post_id)
+SELECT ff.user_id, p.id
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = $1 AND p.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET created_at = users_reads.created_at
+`
+
+type MarkPostReadParams struct {
+	UserID int64 `json:"user_id"`
+	PostID int64 `json:"post_id"`
+}
+
+func (q *Queries) MarkPostRead(ctx context.Context, arg MarkPostReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostRead, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markPostUnread = `-- name: MarkPostUnread :exec
+DELETE FROM users_reads
+WHERE user_id = $1 AND post_id = $2
+`
+
+type MarkPostUnreadParams struct {
+	UserID int64 `json:"user_id"`
+	PostID int64 `json:"post_id"`
+}
+
+func (q *Queries) MarkPostUnread(ctx context.Context, arg MarkPostUnreadParams) error {
+	_, err := q.db.ExecContext(ctx, markPostUnread, arg.UserID, arg.PostID)
+	return err
+}
+
+const markPostsRead = `-- name: MarkPostsRead :execrows
+INSERT INTO users_reads (user_id, post_id)
+SELECT ff.user_id, p.id
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = $1
+  AND ($2::bigint IS NULL OR p.feed_id = $2::bigint)
+  AND ($3::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = $3::bigint
+  ))
+  AND ($4::timestamptz IS NULL OR p.published_at < $4::timestamptz)
+ON CONFLICT (user_id, post_id) DO NOTHING
+`
+
+type MarkPostsReadParams struct {
+	UserID    int64         `json:"user_id"`
+	FeedID    sql.NullInt64 `json:"feed_id"`
+	FolderID  sql.NullInt64 `json:"folder_id"`
+	OlderThan sql.NullTime  `json:"older_than"`
+}
+
+func (q *Queries) MarkPostsRead(ctx context.Context, arg MarkPostsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostsRead,
+		arg.UserID,
+		arg.FeedID,
+		arg.FolderID,
+		arg.OlderThan,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const unbookmarkPost = `-- name: UnbookmarkPost :exec
 DELETE FROM users_bookmarks
 WHERE user_id = $1 AND post_id = $2
@@ -11,19 +11,25 @@ import (
 )
 
 const createSession = `-- name: CreateSession :one
-INSERT INTO sessions (token, user_id, expires_at)
-VALUES ($1, $2, $3)
-RETURNING id, token, user_id, created_at, expires_at
+INSERT INTO sessions (token, user_id, expires_at, csrf_token)
+VALUES ($1, $2, $3, $4)
+RETURNING id, token, user_id, created_at, expires_at, csrf_token
 `
 
 type CreateSessionParams struct {
 	Token     string    `json:"token"`
 	UserID    int64     `json:"user_id"`
 	ExpiresAt time.Time `json:"expires_at"`
+	CsrfToken string    `json:"csrf_token"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
-	row := q.db.QueryRowContext(ctx, createSession, arg.Token, arg.UserID, arg.ExpiresAt)
+	row := q.db.QueryRowContext(ctx, createSession,
+		arg.Token,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.CsrfToken,
+	)
 	var i Session
 	err := row.Scan(
 		&i.ID,
@@ -31,6 +37,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.UserID,
 		&i.CreatedAt,
 		&i.ExpiresAt,
+		&i.CsrfToken,
 	)
 	return i, err
 }
@@ -66,7 +73,7 @@ func (q *Queries) DeleteUserSessions(ctx context.Context, userID int64) error {
 }
 
 const getSessionByToken = `-- name: GetSessionByToken :one
-SELECT s.id, s.token, s.user_id, s.created_at, s.expires_at,
+SELECT s.id, s.token, s.user_id, s.created_at, s.expires_at, s.csrf_token,
        u.id AS user_id_2, u.name, u.email, u.sub, u.created_at AS user_created_at, u.updated_at AS user_updated_at
 FROM sessions s
 INNER JOIN users u ON s.user_id = u.id
@@ -79,6 +86,7 @@ type GetSessionByTokenRow struct {
 	UserID        int64     `json:"user_id"`
 	CreatedAt     time.Time `json:"created_at"`
 	ExpiresAt     time.Time `json:"expires_at"`
+	CsrfToken     string    `json:"csrf_token"`
 	UserID2       int64     `json:"user_id_2"`
 	Name          string    `json:"name"`
 	Email         string    `json:"email"`
@@ -96,6 +104,7 @@ func (q *Queries) GetSessionByToken(ctx context.Context, token string) (GetSessi
 		&i.UserID,
 		&i.CreatedAt,
 		&i.ExpiresAt,
+		&i.CsrfToken,
 		&i.UserID2,
 		&i.Name,
 		&i.Email,
@@ -0,0 +1,136 @@
+// Package filter matches posts against the keyword and regular expression
+// rules users define to mute, mark read or bookmark posts automatically.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Fields a rule can match on. FieldAny matches any of the others.
+const (
+	FieldTitle    = "title"
+	FieldAuthor   = "author"
+	FieldContent  = "content"
+	FieldUrl      = "url"
+	FieldCategory = "category"
+	FieldAny      = "any"
+)
+
+// Ways a rule's pattern is matched.
+const (
+	MatchKeyword = "keyword"
+	MatchRegex   = "regex"
+)
+
+// Actions taken on the posts a rule matches.
+const (
+	ActionHide     = "hide"
+	ActionRead     = "read"
+	ActionBookmark = "bookmark"
+)
+
+var (
+	Fields  = []string{FieldAny, FieldTitle, FieldAuthor, FieldContent, FieldUrl, FieldCategory}
+	Matches = []string{MatchKeyword, MatchRegex}
+	Actions = []string{ActionHide, ActionRead, ActionBookmark}
+)
+
+// maxPatternLength caps the patterns users can save.
+const maxPatternLength = 500
+
+// Post holds the parts of a post rules can look at. Content is HTML.
+type Post struct {
+	Title      string
+	Author     string
+	Url        string
+	Content    string
+	Categories []string
+}
+
+// Matcher is a compiled rule pattern.
+type Matcher struct {
+	field   string
+	keyword string
+	re      *regexp.Regexp
+}
+
+// Compile validates a rule and prepares its pattern. Keywords match
+// case-insensitively anywhere in the field; regular expressions use Go
+// syntax and are case-sensitive unless they start with (?i).
+func Compile(field, match, pattern string) (*Matcher, error) {
+	if !slices.Contains(Fields, field) {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+	if len(pattern) > maxPatternLength {
+		return nil, errors.New("pattern is too long")
+	}
+	m := &Matcher{field: field}
+	switch match {
+	case MatchKeyword:
+		m.keyword = strings.ToLower(pattern)
+	case MatchRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		m.re = re
+	default:
+		return nil, fmt.Errorf("unknown match type %q", match)
+	}
+	return m, nil
+}
+
+// ValidAction reports whether action is one of Actions.
+func ValidAction(action string) bool {
+	return slices.Contains(Actions, action)
+}
+
+// Match reports whether the post matches the rule.
+func (m *Matcher) Match(p Post) bool {
+	switch m.field {
+	case FieldTitle:
+		return m.matchString(p.Title)
+	case FieldAuthor:
+		return m.matchString(p.Author)
+	case FieldUrl:
+		return m.matchString(p.Url)
+	case FieldContent:
+		return m.matchString(Text(p.Content))
+	case FieldCategory:
+		return slices.ContainsFunc(p.Categories, m.matchString)
+	}
+	return m.matchString(p.Title) || m.matchString(p.Author) || m.matchString(p.Url) ||
+		m.matchString(Text(p.Content)) || slices.ContainsFunc(p.Categories, m.matchString)
+}
+
+func (m *Matcher) matchString(s string) bool {
+	if m.re != nil {
+		return m.re.MatchString(s)
+	}
+	return strings.Contains(strings.ToLower(s), m.keyword)
+}
+
+// Text returns the text of an HTML fragment, so rules don't match on markup.
+func Text(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			b.Write(z.Text())
+			b.WriteByte(' ')
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package filter
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	post := Post{
+		Title:      "Weekly Roundup #42",
+		Author:     "Jane Doe",
+		Url:        "https://example.com/sponsored/deal",
+		Content:    `<p>Big <a href="https://crypto.example">news</a> about Rust</p>`,
+		Categories: []string{"Sponsored", "Tech"},
+	}
+	tests := []struct {
+		field, match, pattern string
+		want                  bool
+	}{
+		{FieldTitle, MatchKeyword, "weekly roundup", true},
+		{FieldTitle, MatchKeyword, "monthly", false},
+		{FieldTitle, MatchRegex, `#\d+$`, true},
+		{FieldTitle, MatchRegex, `^weekly`, false},
+		{FieldTitle, MatchRegex, `(?i)^weekly`, true},
+		{FieldAuthor, MatchKeyword, "jane", true},
+		{FieldUrl, MatchKeyword, "/sponsored/", true},
+		{FieldContent, MatchKeyword, "rust", true},
+		{FieldContent, MatchKeyword, "crypto", false},
+		{FieldCategory, MatchKeyword, "sponsored", true},
+		{FieldCategory, MatchRegex, "^Tech$", true},
+		{FieldCategory, MatchRegex, "^Te$", false},
+		{FieldAny, MatchKeyword, "deal", true},
+		{FieldAny, MatchKeyword, "python", false},
+	}
+	for _, tt := range tests {
+		m, err := Compile(tt.field, tt.match, tt.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q, %q, %q): %v", tt.field, tt.match, tt.pattern, err)
+		}
+		if got := m.Match(post); got != tt.want {
+			t.Errorf("%s %s %q = %v, want %v", tt.field, tt.match, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct{ field, match, pattern string }{
+		{"body", MatchKeyword, "x"},
+		{FieldTitle, "glob", "x"},
+		{FieldTitle, MatchKeyword, "   "},
+		{FieldTitle, MatchRegex, "(unclosed"},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.field, tt.match, tt.pattern); err == nil {
+			t.Errorf("Compile(%q, %q, %q) should fail", tt.field, tt.match, tt.pattern)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package opml reads and writes OPML subscription lists. Outlines with an
+// xmlUrl are feeds; outlines without one are groups, which map to folders.
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Subscription is a feed listed in an OPML file together with the groups
+// it appears in.
+type Subscription struct {
+	Url     string
+	Title   string
+	SiteUrl string
+	Folders []string
+}
+
+var ErrNoSubscriptions = errors.New("opml: no subscriptions found")
+
+// Parse reads an OPML document and returns its feeds in document order. A
+// feed listed in several groups is returned once with all of its folders;
+// for nested groups the innermost group name is used.
+func Parse(r io.Reader) ([]Subscription, error) {
+	var doc OPML
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+	d.Strict = false
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var subs []Subscription
+	index := map[string]int{}
+	var walk func(outlines []Outline, folder string)
+	walk = func(outlines []Outline, folder string) {
+		for _, o := range outlines {
+			url := strings.TrimSpace(o.XMLURL)
+			if url == "" {
+				name := strings.TrimSpace(o.Text)
+				if name == "" {
+					name = strings.TrimSpace(o.Title)
+				}
+				if name == "" {
+					name = folder
+				}
+				walk(o.Outlines, name)
+				continue
+			}
+			i, ok := index[url]
+			if !ok {
+				title := strings.TrimSpace(o.Title)
+				if title == "" {
+					title = strings.TrimSpace(o.Text)
+				}
+				i = len(subs)
+				index[url] = i
+				subs = append(subs, Subscription{
+					Url:     url,
+					Title:   title,
+					SiteUrl: strings.TrimSpace(o.HTMLURL),
+				})
+			}
+			if folder != "" && !slices.Contains(subs[i].Folders, folder) {
+				subs[i].Folders = append(subs[i].Folders, folder)
+			}
+			// Some exporters nest feeds inside feed outlines.
+			walk(o.Outlines, folder)
+		}
+	}
+	walk(doc.Body.Outlines, "")
+
+	if len(subs) == 0 {
+		return nil, ErrNoSubscriptions
+	}
+	return subs, nil
+}
+
+// Build creates an OPML document for subs. Feeds without folders are listed
+// at the top level, the rest under one group per folder.
+func Build(title string, subs []Subscription, now time.Time) OPML {
+	doc := OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: now.UTC().Format(time.RFC1123Z),
+		},
+	}
+	groups := map[string]int{}
+	for _, s := range subs {
+		outline := Outline{
+			Text:    s.Title,
+			Title:   s.Title,
+			Type:    "rss",
+			XMLURL:  s.Url,
+			HTMLURL: s.SiteUrl,
+		}
+		if len(s.Folders) == 0 {
+			doc.Body.Outlines = append(doc.Body.Outlines, outline)
+			continue
+		}
+		for _, folder := range s.Folders {
+			i, ok := groups[folder]
+			if !ok {
+				i = len(doc.Body.Outlines)
+				groups[folder] = i
+				doc.Body.Outlines = append(doc.Body.Outlines, Outline{Text: folder, Title: folder})
+			}
+			doc.Body.Outlines[i].Outlines = append(doc.Body.Outlines[i].Outlines, outline)
+		}
+	}
+	return doc
+}
+
+// Write encodes doc as an indented XML document.
+func Write(w io.Writer, doc OPML) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,67 @@
+package opml
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Loose" title="Loose feed" type="rss" xmlUrl="https://loose.example/feed" htmlUrl="https://loose.example/"/>
+    <outline text="Tech">
+      <outline text="Go" type="rss" xmlUrl="https://go.dev/blog/feed.atom" htmlUrl="https://go.dev/blog"/>
+      <outline text="Deep">
+        <outline text="Nested" xmlUrl="https://nested.example/rss"/>
+      </outline>
+    </outline>
+    <outline title="News">
+      <outline text="Go again" xmlUrl="https://go.dev/blog/feed.atom"/>
+    </outline>
+  </body>
+</opml>`
+
+func TestParse(t *testing.T) {
+	subs, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Subscription{
+		{Url: "https://loose.example/feed", Title: "Loose feed", SiteUrl: "https://loose.example/"},
+		{Url: "https://go.dev/blog/feed.atom", Title: "Go", SiteUrl: "https://go.dev/blog", Folders: []string{"Tech", "News"}},
+		{Url: "https://nested.example/rss", Title: "Nested", Folders: []string{"Deep"}},
+	}
+	if !reflect.DeepEqual(subs, want) {
+		t.Fatalf("got %+v\nwant %+v", subs, want)
+	}
+
+	if _, err := Parse(strings.NewReader(`<opml><body><outline text="empty"/></body></opml>`)); err != ErrNoSubscriptions {
+		t.Fatalf("expected ErrNoSubscriptions, got %v", err)
+	}
+	if _, err := Parse(strings.NewReader(`not xml`)); err == nil {
+		t.Fatal("expected an error for invalid input")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	subs := []Subscription{
+		{Url: "https://a.example/feed", Title: "A & B", SiteUrl: "https://a.example/"},
+		{Url: "https://b.example/feed", Title: "B", Folders: []string{"Tech", "Daily"}},
+		{Url: "https://c.example/feed", Title: "C", Folders: []string{"Tech"}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, Build("Nyusu", subs, time.Date(2024, 7, 12, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, subs) {
+		t.Fatalf("got %+v\nwant %+v", got, subs)
+	}
+}
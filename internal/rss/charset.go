@@ -0,0 +1,80 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var prologEncoding = regexp.MustCompile(`^\s*<\?xml[^>]*encoding=["']([A-Za-z0-9._:-]+)["']`)
+
+// toUTF8 converts a feed body to UTF-8 before it is parsed. The charset from
+// the HTTP Content-Type wins, then a byte order mark, then the encoding in the
+// XML prolog. A header claiming UTF-8 is ignored when the body isn't valid
+// UTF-8, since many servers send that charset by default.
+func toUTF8(data []byte, contentType string) ([]byte, error) {
+	label := ""
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		label = params["charset"]
+	}
+	if isUTF8(label) && !utf8.Valid(data) {
+		label = ""
+	}
+
+	var enc encoding.Encoding
+	switch {
+	case label != "":
+		e, err := htmlindex.Get(label)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported charset %q", label)
+		}
+		enc = e
+	case bytes.HasPrefix(data, []byte("\xef\xbb\xbf")):
+		enc = unicode.UTF8BOM
+	case bytes.HasPrefix(data, []byte("\xff\xfe")), bytes.HasPrefix(data, []byte("\xfe\xff")):
+		enc = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	default:
+		head := data
+		if len(head) > 1024 {
+			head = head[:1024]
+		}
+		m := prologEncoding.FindSubmatch(head)
+		if m == nil || isUTF8(string(m[1])) {
+			return data, nil
+		}
+		e, err := htmlindex.Get(string(m[1]))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported charset %q", m[1])
+		}
+		enc = e
+	}
+
+	if enc == encoding.Nop || enc == unicode.UTF8 {
+		return data, nil
+	}
+	return enc.NewDecoder().Bytes(data)
+}
+
+func isUTF8(label string) bool {
+	label = strings.ToLower(strings.TrimSpace(label))
+	return label == "utf-8" || label == "utf8"
+}
+
+// unmarshalXML decodes a body that toUTF8 already converted, so the encoding
+// named in the prolog is accepted as is instead of being applied again.
+func unmarshalXML(data []byte, v any) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return d.Decode(v)
+}
@@ -0,0 +1,162 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Candidate is a feed found while looking for feeds behind a website URL.
+// Feed is set when discovery already downloaded and parsed it.
+type Candidate struct {
+	Url   string `json:"url"`
+	Title string `json:"title"`
+	Type  string `json:"type"`
+	Feed  *Rss   `json:"-"`
+}
+
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+}
+
+// commonFeedPaths are probed when a page doesn't advertise its feeds.
+var commonFeedPaths = []string{
+	"/feed",
+	"/rss.xml",
+	"/atom.xml",
+	"/feed.xml",
+	"/index.xml",
+	"/rss",
+	"/feed.json",
+}
+
+// probeTimeout bounds the probes of the common feed paths, which run at
+// once.
+const probeTimeout = 5 * time.Second
+
+var errNoFeedsFound = errors.New("couldn't find a feed at that url")
+
+// Discover finds the feeds behind pageUrl. A URL that already is a feed is
+// returned, parsed, as the only candidate. Otherwise the page's
+// <link rel="alternate"> tags are used, and when there are none the common
+// feed paths of the site are probed.
+func Discover(ctx context.Context, pageUrl string) ([]Candidate, error) {
+	resp, err := get(ctx, pageUrl, Validators{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errNoFeedsFound
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.New("couldn't read the request body")
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if feed, err := parseFeed(data, contentType); err == nil {
+		return []Candidate{{Url: pageUrl, Title: feed.Channel.Title, Feed: &feed}}, nil
+	}
+
+	base := resp.Request.URL
+	candidates := linkCandidates(data, base)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	return probeFeeds(ctx, base)
+}
+
+// probeFeeds looks for a feed at the common feed paths of a site, preferring
+// the paths listed first.
+func probeFeeds(ctx context.Context, base *url.URL) ([]Candidate, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	found := make([]*Candidate, len(commonFeedPaths))
+	var wg sync.WaitGroup
+	for i, p := range commonFeedPaths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u := base.ResolveReference(&url.URL{Path: p}).String()
+			feed, err := DataFromFeedContext(ctx, u)
+			if err == nil {
+				found[i] = &Candidate{Url: u, Title: feed.Channel.Title, Feed: &feed}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, c := range found {
+		if c != nil {
+			return []Candidate{*c}, nil
+		}
+	}
+	return nil, errNoFeedsFound
+}
+
+// linkCandidates collects the feeds advertised by an HTML page through
+// <link rel="alternate"> tags, resolving their hrefs against the page URL.
+func linkCandidates(data []byte, base *url.URL) []Candidate {
+	candidates := []Candidate{}
+	seen := map[string]bool{}
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return candidates
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			switch t.DataAtom {
+			case atom.Base:
+				if href := attr(t, "href"); href != "" {
+					if u, err := base.Parse(href); err == nil {
+						base = u
+					}
+				}
+			case atom.Link:
+				rel := strings.Fields(strings.ToLower(attr(t, "rel")))
+				typ := strings.ToLower(strings.TrimSpace(attr(t, "type")))
+				href := attr(t, "href")
+				if href == "" || !feedLinkTypes[typ] || !slices.Contains(rel, "alternate") {
+					continue
+				}
+				u, err := base.Parse(href)
+				if err != nil || seen[u.String()] {
+					continue
+				}
+				seen[u.String()] = true
+				candidates = append(candidates, Candidate{
+					Url:   u.String(),
+					Title: attr(t, "title"),
+					Type:  typ,
+				})
+			case atom.Body:
+				// Feed links belong in the head, stop before the page body.
+				return candidates
+			}
+		}
+	}
+}
+
+func attr(t html.Token, name string) string {
+	for _, a := range t.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+)
+
+// JSONFeed is a JSON Feed 1.0 / 1.1 document, see https://jsonfeed.org.
+type JSONFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageUrl string           `json:"home_page_url"`
+	FeedUrl     string           `json:"feed_url"`
+	Description string           `json:"description"`
+	Icon        string           `json:"icon"`
+	Language    string           `json:"language"`
+	Author      *JSONFeedAuthor  `json:"author"`
+	Authors     []JSONFeedAuthor `json:"authors"`
+	Items       []JSONFeedItem   `json:"items"`
+}
+
+type JSONFeedAuthor struct {
+	Name   string `json:"name"`
+	Url    string `json:"url"`
+	Avatar string `json:"avatar"`
+}
+
+type JSONFeedItem struct {
+	ID            json.RawMessage      `json:"id"`
+	Url           string               `json:"url"`
+	ExternalUrl   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHtml   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	Image         string               `json:"image"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *JSONFeedAuthor      `json:"author"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+	Tags          []string             `json:"tags"`
+}
+
+type JSONFeedAttachment struct {
+	Url               string `json:"url"`
+	MimeType          string `json:"mime_type"`
+	Title             string `json:"title"`
+	SizeInBytes       int64  `json:"size_in_bytes"`
+	DurationInSeconds int64  `json:"duration_in_seconds"`
+}
+
+// isJSONFeed reports whether a response looks like a JSON Feed, going by the
+// Content-Type first and falling back to sniffing the body.
+func isJSONFeed(data []byte, contentType string) bool {
+	ct := strings.ToLower(contentType)
+	if strings.Contains(ct, "json") {
+		return true
+	}
+	if strings.Contains(ct, "xml") {
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("{"))
+}
+
+func parseJSONFeed(data []byte) (Rss, error) {
+	var feed JSONFeed
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &feed); err != nil {
+		return Rss{}, err
+	}
+	if !strings.HasPrefix(feed.Version, "https://jsonfeed.org/version/") {
+		return Rss{}, errNotAFeed
+	}
+
+	rss := Rss{
+		XMLName: xml.Name{Local: "rss"},
+		Version: "2.0",
+	}
+	rss.Channel.Title = feed.Title
+	rss.Channel.Link = feed.HomePageUrl
+	rss.Channel.Description = feed.Description
+	rss.Channel.Language = feed.Language
+	rss.Channel.Image.Url = feed.Icon
+	rss.Channel.Image.Title = feed.Title
+
+	feedAuthor := jsonFeedAuthorNames(feed.Author, feed.Authors)
+	for _, item := range feed.Items {
+		entry := Entry{
+			Title:       item.Title,
+			Url:         item.Url,
+			Description: item.Summary,
+			Content:     item.ContentHtml,
+			Published:   item.DatePublished,
+			Updated:     item.DateModified,
+			Author:      jsonFeedAuthorNames(item.Author, item.Authors),
+			Categories:  item.Tags,
+		}
+		if entry.Url == "" {
+			entry.Url = item.ExternalUrl
+		}
+		if entry.Url == "" {
+			entry.Url = jsonFeedItemID(item.ID)
+		}
+		if entry.Content == "" {
+			entry.Content = textToHTML(item.ContentText)
+		}
+		if entry.Description == "" {
+			entry.Description = item.ContentText
+		}
+		if entry.Author == "" {
+			entry.Author = feedAuthor
+		}
+		for _, a := range item.Attachments {
+			entry.Enclosures = append(entry.Enclosures, Enclosure{
+				Url:       a.Url,
+				Type:      a.MimeType,
+				Length:    a.SizeInBytes,
+				Duration:  a.DurationInSeconds,
+				Thumbnail: item.Image,
+			})
+		}
+		rss.Channel.Items = append(rss.Channel.Items, entry)
+	}
+	return rss, nil
+}
+
+// jsonFeedAuthorNames joins the 1.1 authors list, falling back to the 1.0
+// single author object.
+func jsonFeedAuthorNames(author *JSONFeedAuthor, authors []JSONFeedAuthor) string {
+	if len(authors) == 0 && author != nil {
+		authors = []JSONFeedAuthor{*author}
+	}
+	names := []string{}
+	for _, a := range authors {
+		if a.Name != "" {
+			names = append(names, a.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// jsonFeedItemID returns the item id when it is a string. The spec requires
+// that, but some publishers emit numbers, which aren't usable as a post URL.
+func jsonFeedItemID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,167 @@
+package rss
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Enclosure is a media file attached to an item. Length is in bytes and
+// Duration in seconds, zero when unknown.
+type Enclosure struct {
+	Url       string
+	Type      string
+	Length    int64
+	Duration  int64
+	Thumbnail string
+}
+
+// EnclosureTag is the RSS 2.0 <enclosure> element.
+type EnclosureTag struct {
+	Url    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// MediaContent is a Media RSS media:content element.
+type MediaContent struct {
+	Url      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+// MediaGroup is a Media RSS media:group, bundling alternate renditions.
+type MediaGroup struct {
+	Contents  []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnail MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type MediaThumbnail struct {
+	Url string `xml:"url,attr"`
+}
+
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// collectMedia merges <enclosure>, Media RSS and iTunes metadata into
+// Enclosures. Image-only media becomes the thumbnail of the playable files.
+func (e *Entry) collectMedia() {
+	thumbnail := e.MediaThumbnail.Url
+	if thumbnail == "" {
+		thumbnail = e.ITunesImage.Href
+	}
+	duration := parseDuration(e.ITunesDuration)
+	seen := map[string]bool{}
+
+	add := func(enc Enclosure) {
+		if enc.Url == "" || seen[enc.Url] {
+			return
+		}
+		seen[enc.Url] = true
+		enc.Type = mediaType(enc.Type, enc.Url)
+		if strings.HasPrefix(enc.Type, "image/") {
+			if thumbnail == "" {
+				thumbnail = enc.Url
+			}
+			return
+		}
+		if enc.Duration == 0 {
+			enc.Duration = duration
+		}
+		e.Enclosures = append(e.Enclosures, enc)
+	}
+
+	for _, tag := range e.EnclosureTags {
+		add(Enclosure{Url: tag.Url, Type: tag.Type, Length: parseInt(tag.Length)})
+	}
+	contents := e.MediaContents
+	for _, group := range e.MediaGroups {
+		contents = append(contents, group.Contents...)
+		if thumbnail == "" {
+			thumbnail = group.Thumbnail.Url
+		}
+	}
+	for _, mc := range contents {
+		typ := mc.Type
+		if typ == "" && mc.Medium == "image" {
+			typ = "image/*"
+		}
+		add(Enclosure{
+			Url:      mc.Url,
+			Type:     typ,
+			Length:   parseInt(mc.FileSize),
+			Duration: parseDuration(mc.Duration),
+		})
+	}
+
+	for i := range e.Enclosures {
+		if e.Enclosures[i].Thumbnail == "" {
+			e.Enclosures[i].Thumbnail = thumbnail
+		}
+	}
+}
+
+// mediaExtensions covers the usual podcast and video formats, which the
+// system MIME tables of slim container images often lack.
+var mediaExtensions = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".opus": "audio/opus",
+	".wav":  "audio/wav",
+	".flac": "audio/flac",
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".mov":  "video/quicktime",
+	".webm": "video/webm",
+}
+
+// mediaType returns the declared MIME type, guessing it from the file
+// extension when the feed left it out.
+func mediaType(declared, rawUrl string) string {
+	if declared != "" {
+		return strings.ToLower(strings.TrimSpace(declared))
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if t, ok := mediaExtensions[ext]; ok {
+		return t
+	}
+	t, _, _ := strings.Cut(mime.TypeByExtension(ext), ";")
+	return t
+}
+
+// parseDuration reads durations written as seconds, MM:SS or HH:MM:SS.
+func parseDuration(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	var total float64
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return int64(total)
+}
+
+func parseInt(value string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
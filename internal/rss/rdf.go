@@ -0,0 +1,57 @@
+package rss
+
+import "encoding/xml"
+
+// RDF is an RSS 1.0 document. Unlike RSS 2.0 the items are siblings of the
+// channel instead of its children, and dates and authors come from Dublin Core.
+type RDF struct {
+	XMLName xml.Name `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+	Channel struct {
+		Title           string `xml:"title"`
+		Link            string `xml:"link"`
+		Description     string `xml:"description"`
+		Language        string `xml:"http://purl.org/dc/elements/1.1/ language"`
+		UpdatePeriod    string `xml:"updatePeriod"`
+		UpdateFrequency string `xml:"updateFrequency"`
+	} `xml:"channel"`
+	Image Image     `xml:"image"`
+	Items []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+}
+
+// toRss converts an RSS 1.0 document into the RSS 2.0 model.
+func (r RDF) toRss() Rss {
+	rss := Rss{
+		XMLName: xml.Name{Local: "rss"},
+		Version: "2.0",
+	}
+	rss.Channel.Title = r.Channel.Title
+	rss.Channel.Link = r.Channel.Link
+	rss.Channel.Description = r.Channel.Description
+	rss.Channel.Language = r.Channel.Language
+	rss.Channel.Image = r.Image
+	rss.Channel.UpdatePeriod = r.Channel.UpdatePeriod
+	rss.Channel.UpdateFrequency = r.Channel.UpdateFrequency
+
+	for _, item := range r.Items {
+		rss.Channel.Items = append(rss.Channel.Items, Entry{
+			Title:          item.Title,
+			Url:            item.Link,
+			Description:    item.Description,
+			Date:           item.Date,
+			Creator:        item.Creator,
+			ContentEncoded: item.Content,
+			Categories:     item.Subjects,
+		})
+	}
+	return rss
+}
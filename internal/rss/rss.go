@@ -1,11 +1,17 @@
 package rss
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Entry struct {
@@ -14,24 +20,99 @@ type Entry struct {
 	Url         string `xml:"link"`
 	Description string `xml:"description"`
 	Published   string `xml:"pubDate"`
-	Content     string `xml:"content"`
-	Creator     string `xml:"creator"`
-	Author      string `xml:"author"`
+	Updated     string `xml:"updated"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	// Media RSS and iTunes elements must come before Content and Author,
+	// which would otherwise also match media:content and itunes:author.
+	EnclosureTags  []EnclosureTag `xml:"enclosure"`
+	MediaContents  []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroups    []MediaGroup   `xml:"http://search.yahoo.com/mrss/ group"`
+	MediaThumbnail MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	ITunesDuration string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesImage    ITunesImage    `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Content        string         `xml:"content"`
+	ContentEncoded string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator        string         `xml:"creator"`
+	Author         string         `xml:"author"`
+	Categories     []string       `xml:"category"`
+	Enclosures     []Enclosure    `xml:"-"`
+}
+
+// FullContent returns the full HTML body of the item, preferring
+// content:encoded over a plain <content> element. It is empty when the
+// feed only carries a description.
+func (e Entry) FullContent() string {
+	if content := strings.TrimSpace(e.ContentEncoded); content != "" {
+		return content
+	}
+	return strings.TrimSpace(e.Content)
+}
+
+// textToHTML escapes plain text and keeps its paragraphs and line breaks.
+func textToHTML(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>"))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+// AtomCategory is an Atom <category>; Label is the human readable form of
+// Term when present.
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// AtomContent is an Atom <content> element. Depending on Type the body is
+// plain text, escaped HTML or inline XHTML markup.
+type AtomContent struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// HTML returns the content as an HTML fragment.
+func (c AtomContent) HTML() string {
+	switch strings.ToLower(strings.TrimSpace(c.Type)) {
+	case "html", "text/html":
+		return strings.TrimSpace(c.Text)
+	case "xhtml", "application/xhtml+xml":
+		return strings.TrimSpace(c.Inner)
+	default:
+		return textToHTML(c.Text)
+	}
 }
 
 type AtomEntry struct {
-	Text      string `xml:",chardata"`
-	Title     string `xml:"title"`
-	Link      struct {
-		Href string `xml:"href,attr"`
-	} `xml:"link"`
-	Summary   string `xml:"summary"`
-	Content   string `xml:"content"`
-	Published string `xml:"published"`
-	Updated   string `xml:"updated"`
+	Text      string      `xml:",chardata"`
+	Title     string      `xml:"title"`
+	Link      []AtomLink  `xml:"link"`
+	Summary   string      `xml:"summary"`
+	Content   AtomContent `xml:"content"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
 	Author    struct {
 		Name string `xml:"name"`
 	} `xml:"author"`
+	Categories []AtomCategory `xml:"category"`
 }
 
 type Image struct {
@@ -51,14 +132,18 @@ type Rss struct {
 		Language    string  `xml:"language"`
 		Image       Image   `xml:"image"`
 		Items       []Entry `xml:"item"`
+		// Publisher hints on how often the feed should be polled.
+		TTL             string `xml:"ttl"`
+		UpdatePeriod    string `xml:"updatePeriod"`
+		UpdateFrequency string `xml:"updateFrequency"`
 	} `xml:"channel"`
 }
 
 type AtomFeed struct {
-	XMLName  xml.Name    `xml:"feed"`
-	Text     string      `xml:",chardata"`
-	Title    string      `xml:"title"`
-	Subtitle string      `xml:"subtitle"`
+	XMLName  xml.Name `xml:"feed"`
+	Text     string   `xml:",chardata"`
+	Title    string   `xml:"title"`
+	Subtitle string   `xml:"subtitle"`
 	Link     []struct {
 		Href string `xml:"href,attr"`
 		Rel  string `xml:"rel,attr"`
@@ -66,37 +151,196 @@ type AtomFeed struct {
 	Entries []AtomEntry `xml:"entry"`
 }
 
+// Validators are the HTTP cache validators a server sent along with a feed.
+// They are sent back on the next fetch so unchanged feeds can answer 304.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Result is the outcome of a conditional feed fetch. When NotModified is set
+// the server answered 304 and Feed is empty.
+type Result struct {
+	Feed        Rss
+	NotModified bool
+	Validators  Validators
+	// MaxAge is how long the response may be cached according to its
+	// Cache-Control or Expires headers, zero if the server didn't say.
+	MaxAge time.Duration
+}
+
+// UpdateHint returns the minimum polling interval the publisher asks for via
+// <ttl> or the syndication module, zero when the feed gives no hint.
+func (r Rss) UpdateHint() time.Duration {
+	var hint time.Duration
+	if ttl, err := strconv.Atoi(strings.TrimSpace(r.Channel.TTL)); err == nil && ttl > 0 {
+		hint = time.Duration(ttl) * time.Minute
+	}
+
+	var period time.Duration
+	switch strings.ToLower(strings.TrimSpace(r.Channel.UpdatePeriod)) {
+	case "hourly":
+		period = time.Hour
+	case "daily":
+		period = 24 * time.Hour
+	case "weekly":
+		period = 7 * 24 * time.Hour
+	case "monthly":
+		period = 30 * 24 * time.Hour
+	case "yearly":
+		period = 365 * 24 * time.Hour
+	}
+	if period > 0 {
+		frequency, err := strconv.Atoi(strings.TrimSpace(r.Channel.UpdateFrequency))
+		if err != nil || frequency < 1 {
+			frequency = 1
+		}
+		if d := period / time.Duration(frequency); d > hint {
+			hint = d
+		}
+	}
+	return hint
+}
+
+// cacheMaxAge reads the freshness lifetime from Cache-Control max-age,
+// falling back to Expires.
+func cacheMaxAge(h http.Header) time.Duration {
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	if expires, err := http.ParseTime(h.Get("Expires")); err == nil {
+		if d := time.Until(expires); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
 func DataFromFeed(url string) (Rss, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
+	return DataFromFeedContext(context.Background(), url)
+}
+
+// DataFromFeedContext is DataFromFeed for fetches made while a request
+// waits, which stop when ctx is done.
+func DataFromFeedContext(ctx context.Context, url string) (Rss, error) {
+	res, err := fetchFeed(ctx, url, Validators{})
+	if err != nil {
+		return Rss{}, err
+	}
+	return res.Feed, nil
+}
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// get requests url, sending the given validators as If-None-Match /
+// If-Modified-Since.
+func get(ctx context.Context, url string, v Validators) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return Rss{}, errors.New("couldn't create request")
+		return nil, errors.New("couldn't create request")
 	}
 	// Set a proper User-Agent to avoid being blocked by servers
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Nyusu RSS Reader/1.0)")
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return Rss{}, errors.New("couldn't fetch the url")
+		return nil, errors.New("couldn't fetch the url")
+	}
+	return resp, nil
+}
+
+// FetchFeed downloads and parses the feed at url, sending the given
+// validators as If-None-Match / If-Modified-Since.
+func FetchFeed(url string, v Validators) (Result, error) {
+	return fetchFeed(context.Background(), url, v)
+}
+
+func fetchFeed(ctx context.Context, url string, v Validators) (Result, error) {
+	resp, err := get(ctx, url, v)
+	if err != nil {
+		return Result{}, err
 	}
 	defer resp.Body.Close()
 
+	res := Result{
+		Validators: Validators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+		MaxAge: cacheMaxAge(resp.Header),
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		// Servers aren't required to repeat the validators on a 304.
+		if res.Validators.ETag == "" {
+			res.Validators.ETag = v.ETag
+		}
+		if res.Validators.LastModified == "" {
+			res.Validators.LastModified = v.LastModified
+		}
+		res.NotModified = true
+		return res, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Print(err)
-		return Rss{}, errors.New("couldn't read the request body")
+		return Result{}, errors.New("couldn't read the request body")
+	}
+
+	feed, err := parseFeed(data, resp.Header.Get("Content-Type"))
+	if err != nil {
+		dataStr := string(data)
+		if len(dataStr) > 100 {
+			dataStr = dataStr[:100] + "..."
+		}
+		log.Printf("Feed parsing failed for URL %s. Response content: %s", url, dataStr)
+		return Result{}, err
+	}
+	res.Feed = feed
+	return res, nil
+}
+
+var errNotAFeed = errors.New("couldn't parse feed - not a valid RSS, Atom or JSON feed")
+
+func parseFeed(data []byte, contentType string) (Rss, error) {
+	if isJSONFeed(data, contentType) {
+		return parseJSONFeed(data)
+	}
+
+	data, err := toUTF8(data, contentType)
+	if err != nil {
+		return Rss{}, err
 	}
 
 	// Try RSS first
 	var rssFeed *Rss
-	err = xml.Unmarshal(data, &rssFeed)
+	err = unmarshalXML(data, &rssFeed)
 	if err == nil {
+		for i := range rssFeed.Channel.Items {
+			rssFeed.Channel.Items[i].collectMedia()
+		}
 		return *rssFeed, nil
 	}
 
 	// Try Atom feed
 	var atomFeed *AtomFeed
-	err = xml.Unmarshal(data, &atomFeed)
+	err = unmarshalXML(data, &atomFeed)
 	if err == nil {
 		// Convert Atom to RSS format
 		rss := Rss{
@@ -118,23 +362,46 @@ func DataFromFeed(url string) (Rss, error) {
 		for _, entry := range atomFeed.Entries {
 			item := Entry{
 				Title:       entry.Title,
-				Url:         entry.Link.Href,
 				Description: entry.Summary,
-				Content:     entry.Content,
+				Content:     entry.Content.HTML(),
 				Published:   entry.Published,
+				Updated:     entry.Updated,
 				Author:      entry.Author.Name,
 			}
+			for _, c := range entry.Categories {
+				if c.Label != "" {
+					item.Categories = append(item.Categories, c.Label)
+				} else if c.Term != "" {
+					item.Categories = append(item.Categories, c.Term)
+				}
+			}
+			for _, link := range entry.Link {
+				switch link.Rel {
+				case "", "alternate":
+					if item.Url == "" {
+						item.Url = link.Href
+					}
+				case "enclosure":
+					item.Enclosures = append(item.Enclosures, Enclosure{
+						Url:    link.Href,
+						Type:   mediaType(link.Type, link.Href),
+						Length: parseInt(link.Length),
+					})
+				}
+			}
 			rss.Channel.Items = append(rss.Channel.Items, item)
 		}
 
 		return rss, nil
 	}
 
-	// Neither RSS nor Atom worked
-	dataStr := string(data)
-	if len(dataStr) > 100 {
-		dataStr = dataStr[:100] + "..."
+	// Try RSS 1.0 (RDF)
+	var rdfFeed *RDF
+	err = unmarshalXML(data, &rdfFeed)
+	if err == nil {
+		return rdfFeed.toRss(), nil
 	}
-	log.Printf("Feed parsing failed for URL %s. Response content: %s", url, dataStr)
-	return Rss{}, errors.New("couldn't parse feed - not a valid RSS or Atom feed")
+
+	// None of the formats worked
+	return Rss{}, errNotAFeed
 }
@@ -0,0 +1,374 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const rssSample = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Sample</title>
+    <link>https://example.com</link>
+    <item>
+      <title>First</title>
+      <link>https://example.com/first</link>
+      <pubDate>Fri, 12 Jul 2024 13:00:00 +0200</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+func TestFetchFeedConditional(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Fri, 12 Jul 2024 13:00:00 GMT")
+		w.Write([]byte(rssSample))
+	}))
+	defer srv.Close()
+
+	res, err := FetchFeed(srv.URL, Validators{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.NotModified || len(res.Feed.Channel.Items) != 1 {
+		t.Fatalf("expected a parsed feed, got %+v", res)
+	}
+	if res.Validators.ETag != `"v1"` {
+		t.Fatalf("unexpected etag %q", res.Validators.ETag)
+	}
+
+	res, err = FetchFeed(srv.URL, res.Validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.NotModified {
+		t.Fatal("expected a 304")
+	}
+	if res.Validators.LastModified != "Fri, 12 Jul 2024 13:00:00 GMT" {
+		t.Fatalf("validators should survive a 304, got %+v", res.Validators)
+	}
+}
+
+func TestUpdateHint(t *testing.T) {
+	var r Rss
+	r.Channel.TTL = "60"
+	if got := r.UpdateHint(); got != time.Hour {
+		t.Fatalf("ttl: got %s", got)
+	}
+	r.Channel.UpdatePeriod = "daily"
+	r.Channel.UpdateFrequency = "2"
+	if got := r.UpdateHint(); got != 12*time.Hour {
+		t.Fatalf("sy:updatePeriod: got %s", got)
+	}
+}
+
+const jsonFeedSample = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Sample",
+  "home_page_url": "https://example.com",
+  "authors": [{"name": "Jane"}],
+  "items": [
+    {
+      "id": "https://example.com/first",
+      "content_html": "<p>Hello</p>",
+      "date_published": "2024-07-12T13:00:00+02:00",
+      "attachments": [
+        {"url": "https://example.com/first.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1024, "duration_in_seconds": 60}
+      ]
+    },
+    {
+      "id": "2",
+      "url": "https://example.com/second",
+      "content_text": "Plain",
+      "authors": [{"name": "Ann"}, {"name": "Bob"}]
+    }
+  ]
+}`
+
+func TestParseJSONFeed(t *testing.T) {
+	feed, err := parseFeed([]byte(jsonFeedSample), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Channel.Title != "Sample" || feed.Channel.Link != "https://example.com" {
+		t.Fatalf("unexpected channel %+v", feed.Channel)
+	}
+	if len(feed.Channel.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(feed.Channel.Items))
+	}
+
+	first := feed.Channel.Items[0]
+	if first.Url != "https://example.com/first" || first.Content != "<p>Hello</p>" || first.Author != "Jane" {
+		t.Fatalf("unexpected first item %+v", first)
+	}
+	if len(first.Enclosures) != 1 || first.Enclosures[0].Type != "audio/mpeg" || first.Enclosures[0].Duration != 60 {
+		t.Fatalf("unexpected enclosures %+v", first.Enclosures)
+	}
+
+	second := feed.Channel.Items[1]
+	if second.Content != "<p>Plain</p>" || second.Author != "Ann, Bob" {
+		t.Fatalf("unexpected second item %+v", second)
+	}
+}
+
+const rdfSample = `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns="http://purl.org/rss/1.0/"
+  xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://example.com/">
+    <title>Sample</title>
+    <link>https://example.com/</link>
+    <description>An RSS 1.0 feed</description>
+    <dc:language>en</dc:language>
+  </channel>
+  <item rdf:about="https://example.com/first">
+    <title>First</title>
+    <link>https://example.com/first</link>
+    <dc:date>2024-07-12T13:00:00+02:00</dc:date>
+    <dc:creator>Jane</dc:creator>
+  </item>
+</rdf:RDF>`
+
+func TestParseRDF(t *testing.T) {
+	feed, err := parseFeed([]byte(rdfSample), "application/rdf+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Channel.Title != "Sample" || feed.Channel.Language != "en" {
+		t.Fatalf("unexpected channel %+v", feed.Channel)
+	}
+	if len(feed.Channel.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(feed.Channel.Items))
+	}
+	item := feed.Channel.Items[0]
+	if item.Url != "https://example.com/first" || item.Date != "2024-07-12T13:00:00+02:00" || item.Creator != "Jane" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestParseLegacyCharsets(t *testing.T) {
+	latin1 := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<rss version=\"2.0\"><channel><title>Caf\xe9</title></channel></rss>")
+	feed, err := parseFeed(latin1, "application/rss+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Channel.Title != "Café" {
+		t.Fatalf("prolog encoding: got %q", feed.Channel.Title)
+	}
+
+	// "Новости" in KOI8-R, declared only by the HTTP header.
+	koi8 := []byte("<rss version=\"2.0\"><channel><title>\xee\xcf\xd7\xcf\xd3\xd4\xc9</title></channel></rss>")
+	feed, err = parseFeed(koi8, "text/xml; charset=KOI8-R")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Channel.Title != "Новости" {
+		t.Fatalf("header charset: got %q", feed.Channel.Title)
+	}
+}
+
+const contentSample = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Sample</title>
+    <item>
+      <title>Encoded</title>
+      <link>https://example.com/encoded</link>
+      <description>Short summary</description>
+      <content:encoded><![CDATA[<p>Full <b>body</b></p>]]></content:encoded>
+    </item>
+  </channel>
+</rss>`
+
+const atomContentSample = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Sample</title>
+  <entry>
+    <title>Html</title>
+    <content type="html">&lt;p&gt;Escaped &amp;amp; html&lt;/p&gt;</content>
+  </entry>
+  <entry>
+    <title>Xhtml</title>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Inline</p></div></content>
+  </entry>
+  <entry>
+    <title>Text</title>
+    <content>1 &lt; 2</content>
+  </entry>
+</feed>`
+
+func TestParseContent(t *testing.T) {
+	feed, err := parseFeed([]byte(contentSample), "application/rss+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := feed.Channel.Items[0].FullContent(); got != "<p>Full <b>body</b></p>" {
+		t.Fatalf("unexpected content:encoded %q", got)
+	}
+	if got := feed.Channel.Items[0].Description; got != "Short summary" {
+		t.Fatalf("unexpected description %q", got)
+	}
+
+	feed, err = parseFeed([]byte(atomContentSample), "application/atom+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"<p>Escaped &amp; html</p>",
+		`<div xmlns="http://www.w3.org/1999/xhtml"><p>Inline</p></div>`,
+		"<p>1 &lt; 2</p>",
+	}
+	for i, item := range feed.Channel.Items {
+		if got := item.FullContent(); got != want[i] {
+			t.Errorf("entry %d: got %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+const categorySample = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Blog</title>
+  <entry>
+    <title>Tagged</title>
+    <category term="go"/>
+    <category term="rel-notes" label="Release notes"/>
+  </entry>
+</feed>`
+
+func TestParseCategories(t *testing.T) {
+	feed, err := parseFeed([]byte(`<rss version="2.0"><channel><item><title>A</title>`+
+		`<category>News</category><category domain="x">Go</category></item></channel></rss>`), "application/rss+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := feed.Channel.Items[0].Categories; !reflect.DeepEqual(got, []string{"News", "Go"}) {
+		t.Fatalf("unexpected RSS categories %q", got)
+	}
+
+	feed, err = parseFeed([]byte(categorySample), "application/atom+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := feed.Channel.Items[0].Categories; !reflect.DeepEqual(got, []string{"go", "Release notes"}) {
+		t.Fatalf("unexpected Atom categories %q", got)
+	}
+}
+
+const podcastSample = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+  xmlns:media="http://search.yahoo.com/mrss/">
+  <channel>
+    <title>Podcast</title>
+    <item>
+      <title>Episode 1</title>
+      <link>https://example.com/ep1</link>
+      <enclosure url="https://example.com/ep1.mp3" length="12345" type="audio/mpeg"/>
+      <itunes:duration>01:02:03</itunes:duration>
+      <itunes:image href="https://example.com/ep1.jpg"/>
+      <itunes:author>Jane</itunes:author>
+    </item>
+    <item>
+      <title>Video</title>
+      <link>https://example.com/video</link>
+      <media:content url="https://example.com/video.mp4" fileSize="999" duration="90"/>
+      <media:content url="https://example.com/poster.jpg" medium="image"/>
+    </item>
+  </channel>
+</rss>`
+
+func TestParseEnclosures(t *testing.T) {
+	feed, err := parseFeed([]byte(podcastSample), "application/rss+xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	episode := feed.Channel.Items[0]
+	want := Enclosure{
+		Url:       "https://example.com/ep1.mp3",
+		Type:      "audio/mpeg",
+		Length:    12345,
+		Duration:  3723,
+		Thumbnail: "https://example.com/ep1.jpg",
+	}
+	if len(episode.Enclosures) != 1 || episode.Enclosures[0] != want {
+		t.Fatalf("unexpected enclosures %+v", episode.Enclosures)
+	}
+	if episode.Author != "Jane" || episode.Content != "" {
+		t.Fatalf("itunes elements leaked into %+v", episode)
+	}
+
+	video := feed.Channel.Items[1]
+	want = Enclosure{
+		Url:       "https://example.com/video.mp4",
+		Type:      "video/mp4",
+		Length:    999,
+		Duration:  90,
+		Thumbnail: "https://example.com/poster.jpg",
+	}
+	if len(video.Enclosures) != 1 || video.Enclosures[0] != want {
+		t.Fatalf("unexpected enclosures %+v", video.Enclosures)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blog", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head>
+<link rel="alternate" type="application/rss+xml" title="Posts" href="/posts.xml">
+<link rel="alternate" type="application/atom+xml" title="Comments" href="comments.atom">
+<link rel="stylesheet" href="/style.css">
+</head><body></body></html>`))
+	})
+	mux.HandleFunc("/plain/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>No links</title></head></html>`))
+	})
+	var feedRequests atomic.Int32
+	mux.HandleFunc("/rss.xml", func(w http.ResponseWriter, r *http.Request) {
+		feedRequests.Add(1)
+		w.Write([]byte(rssSample))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	candidates, err := Discover(context.Background(), srv.URL+"/blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 2 || candidates[0].Url != srv.URL+"/posts.xml" || candidates[1].Url != srv.URL+"/comments.atom" {
+		t.Fatalf("unexpected candidates %+v", candidates)
+	}
+
+	candidates, err = Discover(context.Background(), srv.URL+"/plain/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].Url != srv.URL+"/rss.xml" || candidates[0].Title != "Sample" {
+		t.Fatalf("unexpected probed candidates %+v", candidates)
+	}
+	if candidates[0].Feed == nil {
+		t.Fatal("the probed feed should be returned parsed")
+	}
+
+	feedRequests.Store(0)
+	candidates, err = Discover(context.Background(), srv.URL+"/rss.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].Feed == nil || len(candidates[0].Feed.Channel.Items) == 0 {
+		t.Fatalf("a feed URL should be returned parsed, got %+v", candidates)
+	}
+	if n := feedRequests.Load(); n != 1 {
+		t.Errorf("the feed was requested %d times", n)
+	}
+}
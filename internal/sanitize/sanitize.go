@@ -0,0 +1,352 @@
+// Package sanitize cleans HTML supplied by feeds so it can be rendered inside
+// Nyusu pages. Only an allowlist of tags and attributes survives; everything
+// else is dropped, keeping the text of unknown elements. Relative URLs are
+// made absolute, tracking pixels are removed and iframes are only kept for
+// known embed hosts.
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// allowedTags maps each allowed element to the attributes it may keep.
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"caption":    nil,
+	"cite":       nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"details":    nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"iframe":     {"src", "width", "height", "title", "allowfullscreen"},
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"s":          nil,
+	"small":      nil,
+	"source":     {"src", "type"},
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"summary":    nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan", "scope"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+	"video":      {"src", "poster", "width", "height"},
+	"audio":      {"src"},
+}
+
+// droppedTags are removed together with everything inside them.
+var droppedTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"object":   true,
+	"embed":    true,
+	"applet":   true,
+	"noscript": true,
+	"template": true,
+	"form":     true,
+	"select":   true,
+	"textarea": true,
+	"svg":      true,
+	"math":     true,
+	"head":     true,
+	"title":    true,
+}
+
+// voidTags have no closing tag.
+var voidTags = map[string]bool{
+	"br":     true,
+	"hr":     true,
+	"img":    true,
+	"source": true,
+}
+
+// urlAttrs hold URLs and are checked for a safe scheme.
+var urlAttrs = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"cite":   true,
+	"poster": true,
+}
+
+// embedHosts are the only hosts iframes may point to, matching subdomains
+// too.
+var embedHosts = []string{
+	"youtube.com",
+	"youtube-nocookie.com",
+	"player.vimeo.com",
+	"w.soundcloud.com",
+	"open.spotify.com",
+	"embed.podcasts.apple.com",
+	"bandcamp.com",
+	"codepen.io",
+	"player.twitch.tv",
+}
+
+// trackerHosts serve invisible images that only report reads back to the
+// publisher.
+var trackerHosts = []string{
+	"feeds.feedburner.com",
+	"feedproxy.google.com",
+	"pixel.wp.com",
+	"stats.wordpress.com",
+	"pixel.quantserve.com",
+	"www.google-analytics.com",
+	"ad.doubleclick.net",
+	"feeds.feedblitz.com",
+	"pi.pardot.com",
+	"mailchi.mp",
+}
+
+// HTML returns a sanitized copy of the fragment s. Relative URLs are
+// resolved against baseURL, normally the post link; when baseURL is empty or
+// invalid they are dropped.
+func HTML(s, baseURL string) string {
+	base, err := url.Parse(baseURL)
+	if err != nil || !base.IsAbs() {
+		base = nil
+	}
+	var b strings.Builder
+	z := nethtml.NewTokenizer(strings.NewReader(s))
+	// open is the stack of allowed elements written so far, so stray end
+	// tags can't close markup outside the fragment.
+	var open []string
+	// skipped is the stack of dropped elements whose content is being
+	// skipped; an end tag pops back to its own start tag, so a stray one
+	// can't end the skipping of an enclosing element.
+	var skipped []string
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				if tt == nethtml.StartTagToken {
+					skipped = append(skipped, tok.Data)
+				}
+				continue
+			}
+			if len(skipped) > 0 {
+				continue
+			}
+			attrs, ok := allowedTags[tok.Data]
+			if !ok {
+				continue
+			}
+			tok.Attr = cleanAttrs(tok.Attr, attrs, base)
+			if tok.Data == "img" && isTrackingPixel(tok.Attr) {
+				continue
+			}
+			if tok.Data == "iframe" && !isEmbed(tok.Attr) {
+				// Skip the iframe's fallback content as well.
+				if tt == nethtml.StartTagToken {
+					skipped = append(skipped, tok.Data)
+				}
+				continue
+			}
+			writeStartTag(&b, tok)
+			if !voidTags[tok.Data] {
+				open = append(open, tok.Data)
+			}
+		case nethtml.EndTagToken:
+			if len(skipped) > 0 {
+				// Nested dropped tags of the same name close innermost first.
+				for i := len(skipped) - 1; i >= 0; i-- {
+					if skipped[i] == tok.Data {
+						skipped = skipped[:i]
+						break
+					}
+				}
+				continue
+			}
+			if droppedTags[tok.Data] {
+				continue
+			}
+			i := slices.Index(open, tok.Data)
+			if i < 0 {
+				continue
+			}
+			for len(open) > i {
+				b.WriteString("</" + open[len(open)-1] + ">")
+				open = open[:len(open)-1]
+			}
+		case nethtml.TextToken:
+			if len(skipped) == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+		}
+	}
+	// Close whatever the feed left open.
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// cleanAttrs keeps the allowed attributes, dropping unsafe URLs and making
+// relative ones absolute.
+func cleanAttrs(attrs []nethtml.Attribute, allowed []string, base *url.URL) []nethtml.Attribute {
+	var clean []nethtml.Attribute
+	for _, attr := range attrs {
+		if attr.Namespace != "" || !slices.Contains(allowed, attr.Key) {
+			continue
+		}
+		attr.Val = strings.TrimSpace(attr.Val)
+		if urlAttrs[attr.Key] {
+			val, ok := resolveURL(attr.Val, base)
+			if !ok {
+				continue
+			}
+			attr.Val = val
+		}
+		clean = append(clean, attr)
+	}
+	return clean
+}
+
+func writeStartTag(b *strings.Builder, tok nethtml.Token) {
+	b.WriteString("<" + tok.Data)
+	for _, attr := range tok.Attr {
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	switch tok.Data {
+	case "a":
+		b.WriteString(` rel="noopener noreferrer" target="_blank"`)
+	case "img", "iframe":
+		b.WriteString(` loading="lazy"`)
+	case "video", "audio":
+		b.WriteString(` controls preload="none"`)
+	}
+	if tok.Data == "iframe" {
+		b.WriteString(` sandbox="allow-scripts allow-same-origin allow-popups allow-presentation" referrerpolicy="no-referrer"`)
+	}
+	b.WriteString(">")
+}
+
+// resolveURL returns u made absolute against base, or false when it uses a
+// scheme that could run script or can't be resolved.
+func resolveURL(u string, base *url.URL) (string, bool) {
+	if !safeURL(u) {
+		return "", false
+	}
+	ref, err := url.Parse(u)
+	if err != nil {
+		return "", false
+	}
+	if ref.IsAbs() {
+		return ref.String(), true
+	}
+	if strings.HasPrefix(u, "#") {
+		return u, true
+	}
+	if base == nil {
+		return "", false
+	}
+	return base.ResolveReference(ref).String(), true
+}
+
+// isEmbed reports whether an iframe points to an allowlisted embed host over
+// https.
+func isEmbed(attrs []nethtml.Attribute) bool {
+	u, err := url.Parse(attr(attrs, "src"))
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	return matchHost(u.Hostname(), embedHosts)
+}
+
+// isTrackingPixel reports whether an image is a 1x1 (or smaller) beacon or
+// is served by a known tracker.
+func isTrackingPixel(attrs []nethtml.Attribute) bool {
+	width, werr := strconv.Atoi(strings.TrimSuffix(attr(attrs, "width"), "px"))
+	height, herr := strconv.Atoi(strings.TrimSuffix(attr(attrs, "height"), "px"))
+	if (werr == nil && width <= 1) || (herr == nil && height <= 1) {
+		return true
+	}
+	u, err := url.Parse(attr(attrs, "src"))
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	return matchHost(u.Hostname(), trackerHosts)
+}
+
+func matchHost(host string, hosts []string) bool {
+	host = strings.ToLower(host)
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(attrs []nethtml.Attribute, key string) string {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// safeURL reports whether u is relative or uses a scheme that can't run
+// script.
+func safeURL(u string) bool {
+	// Browsers ignore whitespace and control characters inside schemes.
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, u)
+	i := strings.IndexAny(u, ":/?#")
+	if i < 0 || u[i] != ':' {
+		return true
+	}
+	switch strings.ToLower(u[:i]) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package sanitize
+
+import "testing"
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`<p>Hello <b>world</b></p>`, `<p>Hello <b>world</b></p>`},
+		{`<p onclick="evil()" class="x">Hi</p>`, `<p>Hi</p>`},
+		{`<script>alert(1)</script><p>ok</p>`, `<p>ok</p>`},
+		{`<style>p{}</style>text`, `text`},
+		{`<a href="javascript:alert(1)">x</a>`, `<a rel="noopener noreferrer" target="_blank">x</a>`},
+		{`<a href="java&#09;script:alert(1)">x</a>`, `<a rel="noopener noreferrer" target="_blank">x</a>`},
+		{`<a href="https://example.com/?a=1&amp;b=2">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" rel="noopener noreferrer" target="_blank">x</a>`},
+		{`<img src="/a.png" onerror="x()">`, `<img src="https://example.com/a.png" loading="lazy">`},
+		{`<custom>kept text</custom>`, `kept text`},
+		{`<p>unclosed <em>tags`, `<p>unclosed <em>tags</em></p>`},
+		{`</div></p>stray`, `stray`},
+		{`1 &lt; 2 &amp; <b>3</b>`, `1 &lt; 2 &amp; <b>3</b>`},
+	}
+	for _, tt := range tests {
+		if got := HTML(tt.in, "https://example.com/posts/1"); got != tt.want {
+			t.Errorf("HTML(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLURLs(t *testing.T) {
+	base := "https://example.com/blog/post.html"
+	tests := []struct {
+		in, want string
+	}{
+		{`<a href="other.html">x</a>`, `<a href="https://example.com/blog/other.html" rel="noopener noreferrer" target="_blank">x</a>`},
+		{`<a href="/about">x</a>`, `<a href="https://example.com/about" rel="noopener noreferrer" target="_blank">x</a>`},
+		{`<a href="#note">x</a>`, `<a href="#note" rel="noopener noreferrer" target="_blank">x</a>`},
+		{`<img src="//cdn.example.com/a.png" alt="a">`, `<img src="https://cdn.example.com/a.png" alt="a" loading="lazy">`},
+		{`<video src="clip.mp4" poster="javascript:x"></video>`, `<video src="https://example.com/blog/clip.mp4" controls preload="none"></video>`},
+	}
+	for _, tt := range tests {
+		if got := HTML(tt.in, base); got != tt.want {
+			t.Errorf("HTML(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+		}
+	}
+
+	if got := HTML(`<a href="/about">x</a>`, ""); got != `<a rel="noopener noreferrer" target="_blank">x</a>` {
+		t.Errorf("relative URL without a base should be dropped, got %q", got)
+	}
+}
+
+func TestHTMLEmbedsAndTrackers(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{
+			`<iframe src="https://www.youtube.com/embed/abc" width="560" onload="x()"></iframe>`,
+			`<iframe src="https://www.youtube.com/embed/abc" width="560" loading="lazy" sandbox="allow-scripts allow-same-origin allow-popups allow-presentation" referrerpolicy="no-referrer"></iframe>`,
+		},
+		{`<iframe src="https://evil.example/x">fallback</iframe><p>after</p>`, `<p>after</p>`},
+		{`<iframe src="http://www.youtube.com/embed/abc"></iframe>`, ``},
+		{`<svg><iframe src=x></iframe><text>leaked svg text</text></svg><p>after</p>`, `<p>after</p>`},
+		{`<svg><object></svg>text`, `text`},
+		{`<object><object></object>x<img src="https://example.com/a.jpg"></object><p>after</p>`, `<p>after</p>`},
+		{`<p>a<img src="https://example.com/t.gif" width="1" height="1">b</p>`, `<p>ab</p>`},
+		{`<img src="https://feeds.feedburner.com/~r/x/~4/abc">`, ``},
+		{`<img src="https://pixel.wp.com/b.gif?v=1">`, ``},
+		{`<img src="https://example.com/photo.jpg" width="640">`, `<img src="https://example.com/photo.jpg" width="640" loading="lazy">`},
+	}
+	for _, tt := range tests {
+		if got := HTML(tt.in, "https://example.com/"); got != tt.want {
+			t.Errorf("HTML(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+		}
+	}
+}
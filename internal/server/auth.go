@@ -133,12 +133,20 @@ func (cfg *APIConfig) OIDCCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	csrfToken, err := GenerateSecureToken()
+	if err != nil {
+		log.Print("Failed to generate CSRF token:", err)
+		internalServerErrorHandler(w)
+		return
+	}
+
 	// Create session in database (expires in 3 days)
 	expiresAt := time.Now().Add(72 * time.Hour)
 	_, err = cfg.DB.CreateSession(cfg.ctx, database.CreateSessionParams{
 		Token:     sessionToken,
 		UserID:    user.ID,
 		ExpiresAt: expiresAt,
+		CsrfToken: csrfToken,
 	})
 	if err != nil {
 		log.Print("Failed to create session:", err)
@@ -160,10 +168,9 @@ func (cfg *APIConfig) OIDCCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
-func (cfg *APIConfig) LogoutUser(w http.ResponseWriter, r *http.Request) {
-	cookie, err := r.Cookie(SessionCookieName)
-	if err == nil {
-		err = cfg.DB.DeleteSession(cfg.ctx, cookie.Value)
+func (cfg *APIConfig) logoutUser(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	if auth.IsAuthenticated {
+		err := cfg.DB.DeleteSession(cfg.ctx, auth.SessionData.Token)
 		if err != nil {
 			log.Print("Failed to delete session:", err)
 		}
@@ -183,18 +190,45 @@ func (cfg *APIConfig) LogoutUser(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
+func (cfg *APIConfig) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	cfg.MiddlewareWebAuth(cfg.logoutUser)(w, r)
+}
+
 func (cfg *APIConfig) MiddlewareAuth(handler AuthHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// API tokens take precedence over the session cookie
+		if header := r.Header.Get("Authorization"); header != "" {
+			token, ok := bearerToken(header)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				respondWithError(w, http.StatusUnauthorized, "malformed authorization header")
+				return
+			}
+			user, scope, err := cfg.apiTokenUser(token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				respondWithError(w, http.StatusUnauthorized, "invalid API token")
+				return
+			}
+			if scope != scopeWrite && !safeMethod(r.Method) {
+				respondWithError(w, http.StatusForbidden, "this API token is read-only")
+				return
+			}
+			handler(w, r, user)
+			return
+		}
+
 		cookie, err := r.Cookie(SessionCookieName)
 		if err != nil {
-			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondWithError(w, http.StatusUnauthorized, "authentication required")
 			return
 		}
 
 		// Get session and user from database using the secure token
 		sessionData, err := cfg.DB.GetSessionByToken(cfg.ctx, cookie.Value)
 		if err != nil {
-			// Session not found or expired - clear the cookie and redirect
+			// Session not found or expired - clear the cookie
 			secure, sameSite := cfg.GetSecureCookieSettings()
 			http.SetCookie(w, &http.Cookie{
 				Name:     SessionCookieName,
@@ -205,7 +239,12 @@ func (cfg *APIConfig) MiddlewareAuth(handler AuthHandler) http.HandlerFunc {
 				SameSite: sameSite,
 				MaxAge:   -1,
 			})
-			http.Redirect(w, r, "/", http.StatusMovedPermanently)
+			respondWithError(w, http.StatusUnauthorized, "session expired")
+			return
+		}
+
+		if !safeMethod(r.Method) && !csrfTokenMatches(sessionData.CsrfToken, r.Header.Get(CSRFHeaderName)) {
+			respondWithError(w, http.StatusForbidden, "missing or invalid CSRF token")
 			return
 		}
 
@@ -235,7 +274,7 @@ func (cfg *APIConfig) CORS(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 
 		if r.Method == "OPTIONS" {
 			http.Error(w, "No Content", http.StatusNoContent)
@@ -256,7 +295,7 @@ func (cfg *APIConfig) OPTIONS(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 
 	http.Error(w, "No Content", http.StatusNoContent)
 }
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/subtle"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Every session gets its own CSRF token. Requests that change state with the
+// session cookie must send it back, forms in the csrf_token field and
+// scripts in the X-CSRF-Token header.
+const (
+	CSRFFormField  = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// maxFormSize caps the form bodies read while looking for the CSRF token.
+// The largest form is the OPML upload.
+const maxFormSize = maxOpmlSize
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// csrfTokenMatches compares a submitted token with the session's in
+// constant time.
+func csrfTokenMatches(expected, given string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(given)) == 1
+}
+
+// formCSRFToken reads the token from the X-CSRF-Token header, falling back
+// to the csrf_token field of a url-encoded or multipart form.
+func formCSRFToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	if token := r.Header.Get(CSRFHeaderName); token != "" {
+		return token, nil
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		err = r.ParseMultipartForm(maxFormSize)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return "", err
+	}
+	return r.PostForm.Get(CSRFFormField), nil
+}
+
+func forbiddenHandler(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte("403 Forbidden"))
+}
+
+// csrfFailedPage explains why a form submission was rejected.
+func (cfg *APIConfig) csrfFailedPage(w http.ResponseWriter, auth AuthResult) {
+	t, err := template.ParseFiles("html/layout.html", "html/forbidden.html")
+	if err != nil {
+		log.Println(err)
+		forbiddenHandler(w)
+		return
+	}
+	w.WriteHeader(http.StatusForbidden)
+	err = t.ExecuteTemplate(w, "layout", cfg.baseData(auth))
+	if err != nil {
+		log.Println(err)
+	}
+}
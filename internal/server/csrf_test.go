@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+type sessionStore struct {
+	database.Querier
+	session database.GetSessionByTokenRow
+}
+
+func (s *sessionStore) GetSessionByToken(_ context.Context, token string) (database.GetSessionByTokenRow, error) {
+	if token != s.session.Token {
+		return database.GetSessionByTokenRow{}, sql.ErrNoRows
+	}
+	return s.session, nil
+}
+
+func csrfConfig() *APIConfig {
+	return &APIConfig{ctx: context.Background(), DB: &sessionStore{
+		session: database.GetSessionByTokenRow{Token: "session", CsrfToken: "secret", UserID2: 1},
+	}}
+}
+
+func TestWebAuthCSRF(t *testing.T) {
+	cfg := csrfConfig()
+	called := false
+	handler := cfg.RequireAuth(func(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+		called = true
+		respondOk(w)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		form   url.Values
+		header string
+		want   int
+	}{
+		{"get needs no token", http.MethodGet, nil, "", http.StatusOK},
+		{"missing token", http.MethodPost, url.Values{"rss": {"x"}}, "", http.StatusForbidden},
+		{"wrong token", http.MethodPost, url.Values{CSRFFormField: {"guess"}}, "", http.StatusForbidden},
+		{"form token", http.MethodPost, url.Values{CSRFFormField: {"secret"}}, "", http.StatusOK},
+		{"header token", http.MethodPost, nil, "secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		called = false
+		r := httptest.NewRequest(tt.method, "/feed", strings.NewReader(tt.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "session"})
+		if tt.header != "" {
+			r.Header.Set(CSRFHeaderName, tt.header)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+		if called != (tt.want == http.StatusOK) {
+			t.Errorf("%s: handler called = %v", tt.name, called)
+		}
+	}
+}
+
+func TestAPIAuthCSRF(t *testing.T) {
+	cfg := csrfConfig()
+	handler := cfg.MiddlewareAuth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		respondOk(w)
+	})
+
+	for _, header := range []string{"", "guess", "secret"} {
+		r := httptest.NewRequest(http.MethodDelete, "/v1/posts/bookmarks/1", nil)
+		r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "session"})
+		if header != "" {
+			r.Header.Set(CSRFHeaderName, header)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		want := http.StatusForbidden
+		if header == "secret" {
+			want = http.StatusOK
+		}
+		if w.Code != want {
+			t.Errorf("header %q: got status %d, want %d", header, w.Code, want)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/odin-software/nyusu/internal/database"
 	"github.com/odin-software/nyusu/internal/rss"
@@ -20,168 +23,549 @@ func (cfg *APIConfig) GetAllFeeds2(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		log.Print(err)
-		internalServerErrorHandler(w)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	if len(feeds) < 1 {
-		notFoundHandler(w)
+		respondWithJSON(w, http.StatusOK, []int{})
 		return
 	}
 	respondWithJSON(w, http.StatusOK, feeds)
 }
 
-func (cfg *APIConfig) CreateFeed(w http.ResponseWriter, r *http.Request) {
-	cookie, err := r.Cookie(SessionCookieName)
+var (
+	errFeedNotFound     = errors.New("couldn't find a feed at that url")
+	errFeedUnreadable   = errors.New("couldn't process url")
+	errAlreadyFollowing = errors.New("you're already following this feed")
+)
+
+// resolveFeed finds the feed at url. When url is a web page instead, it
+// follows the feed the page links to, or returns the candidates when the
+// page links to more than one. A feed URL is only downloaded once.
+func resolveFeed(ctx context.Context, url string) (string, rss.Rss, []rss.Candidate, error) {
+	candidates, err := rss.Discover(ctx, url)
 	if err != nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
-		return
+		return url, rss.Rss{}, nil, errFeedNotFound
 	}
-	url := SanitizeInput(r.FormValue("rss"))
-	if url == "" {
-		http.Redirect(w, r, "/add?error=RSS URL is required", http.StatusSeeOther)
-		return
+	if len(candidates) > 1 {
+		return url, rss.Rss{}, candidates, nil
 	}
-
-	rssData, err := rss.DataFromFeed(url)
-	if err != nil {
-		http.Redirect(w, r, "/add?error=couldn't process url", http.StatusSeeOther)
-		return
+	c := candidates[0]
+	if c.Feed != nil {
+		return c.Url, *c.Feed, nil, nil
 	}
-	sessionData, err := cfg.DB.GetSessionByToken(cfg.ctx, cookie.Value)
+	rssData, err := rss.DataFromFeedContext(ctx, c.Url)
 	if err != nil {
-		log.Print("Invalid session:", err)
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
-		return
+		return c.Url, rss.Rss{}, nil, errFeedUnreadable
 	}
+	return c.Url, rssData, nil, nil
+}
 
-	user := database.User{
-		ID:        sessionData.UserID2,
-	}
-
-	existingFeed, err := cfg.DB.GetFeedByUrl(cfg.ctx, url)
-	var feed database.Feed
-
+// subscribe follows the feed at url, creating it first when it is new to
+// Nyusu, and fetches it so the timeline fills right away.
+func (cfg *APIConfig) subscribe(userID int64, url string, rssData rss.Rss) (database.FeedFollow, error) {
+	feed, err := cfg.DB.GetFeedByUrl(cfg.ctx, url)
 	if err != nil {
 		// Feed doesn't exist, create a new one
-		feed, err = cfg.DB.CreateFeed(cfg.ctx, database.CreateFeedParams{
-			Url:         url,
-			Name:        rssData.Channel.Title,
-			Link:        sql.NullString{String: rssData.Channel.Link, Valid: rssData.Channel.Link != ""},
-			Description: sql.NullString{String: rssData.Channel.Description, Valid: true},
-			ImageUrl:    sql.NullString{String: rssData.Channel.Image.Url, Valid: true},
-			ImageText:   sql.NullString{String: rssData.Channel.Image.Title, Valid: true},
-			Language:    sql.NullString{String: rssData.Channel.Language, Valid: true},
-			UserID:      user.ID,
-		})
+		feed, err = cfg.createFeed(url, rssData, userID)
 		if err != nil {
-			log.Print(err)
-			internalServerErrorHandler(w)
-			return
+			return database.FeedFollow{}, err
 		}
-	} else {
-		feed = existingFeed
 	}
+	return cfg.follow(userID, feed, true)
+}
 
-	_, err = cfg.DB.GetFeedFollows(cfg.ctx, database.GetFeedFollowsParams{
-		UserID: user.ID,
+// follow subscribes the user to a stored feed, optionally fetching it.
+func (cfg *APIConfig) follow(userID int64, feed database.Feed, fetch bool) (database.FeedFollow, error) {
+	_, err := cfg.DB.GetFeedFollows(cfg.ctx, database.GetFeedFollowsParams{
+		UserID: userID,
 		FeedID: feed.ID,
 	})
 	if err == nil {
-		http.Redirect(w, r, "/add?error=you're already following this feed", http.StatusSeeOther)
-		return
+		return database.FeedFollow{}, errAlreadyFollowing
 	}
-
-	_, err = cfg.DB.CreateFeedFollows(cfg.ctx, database.CreateFeedFollowsParams{
-		UserID: user.ID,
+	follow, err := cfg.DB.CreateFeedFollows(cfg.ctx, database.CreateFeedFollowsParams{
+		UserID: userID,
 		FeedID: feed.ID,
 	})
+	if err != nil {
+		return database.FeedFollow{}, err
+	}
+	if fetch {
+		cfg.FetchOneFeedSync(feed.ID, feed.Url)
+	}
+	return follow, nil
+}
+
+func (cfg *APIConfig) createFeedPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	url := SanitizeInput(r.FormValue("rss"))
+	if url == "" {
+		http.Redirect(w, r, "/add?error=RSS URL is required", http.StatusSeeOther)
+		return
+	}
+
+	url, rssData, candidates, err := resolveFeed(r.Context(), url)
+	if err != nil {
+		http.Redirect(w, r, "/add?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
+	if len(candidates) > 0 {
+		cfg.renderDiscoveredFeeds(w, auth, url, candidates)
+		return
+	}
+
+	_, err = cfg.subscribe(auth.SessionData.UserID2, url, rssData)
+	if errors.Is(err, errAlreadyFollowing) {
+		http.Redirect(w, r, "/add?error="+err.Error(), http.StatusSeeOther)
+		return
+	}
 	if err != nil {
 		log.Print(err)
 		internalServerErrorHandler(w)
 		return
 	}
-
-	cfg.FetchOneFeedSync(feed.ID, feed.Url)
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
+func (cfg *APIConfig) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.createFeedPage)(w, r)
+}
+
+// createFeed stores a new feed from its parsed data.
+func (cfg *APIConfig) createFeed(url string, rssData rss.Rss, userID int64) (database.Feed, error) {
+	return cfg.DB.CreateFeed(cfg.ctx, database.CreateFeedParams{
+		Url:         url,
+		Name:        rssData.Channel.Title,
+		Link:        sql.NullString{String: rssData.Channel.Link, Valid: rssData.Channel.Link != ""},
+		Description: sql.NullString{String: rssData.Channel.Description, Valid: true},
+		ImageUrl:    sql.NullString{String: rssData.Channel.Image.Url, Valid: true},
+		ImageText:   sql.NullString{String: rssData.Channel.Image.Title, Valid: true},
+		Language:    sql.NullString{String: rssData.Channel.Language, Valid: true},
+		UserID:      userID,
+	})
+}
+
 func (cfg *APIConfig) GetFeedFollowsFromUser(w http.ResponseWriter, r *http.Request, user database.User) {
 	feeds, err := cfg.DB.GetFeedFollowsFromUser(cfg.ctx, user.ID)
 	if err != nil {
 		log.Print(err)
-		internalServerErrorHandler(w)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	if len(feeds) < 1 {
-		notFoundHandler(w)
+		respondWithJSON(w, http.StatusOK, []int{})
 		return
 	}
 	respondWithJSON(w, http.StatusOK, feeds)
 }
 
+// CreateFeedFollows subscribes to a stored feed by feed_id, or to any feed
+// by url. When url is a web page linking to several feeds, it answers 300
+// with the candidates to pick from.
 func (cfg *APIConfig) CreateFeedFollows(w http.ResponseWriter, r *http.Request, user database.User) {
-	var reqFeedFollow *struct {
-		FeedId int64 `json:"feed_id,omitempty"`
+	var req struct {
+		FeedId int64  `json:"feed_id,omitempty"`
+		Url    string `json:"url,omitempty"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	var feedFollow database.FeedFollow
+	switch url := SanitizeInput(req.Url); {
+	case url != "":
+		var rssData rss.Rss
+		var candidates []rss.Candidate
+		url, rssData, candidates, err = resolveFeed(r.Context(), url)
+		if err != nil {
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		if len(candidates) > 0 {
+			respondWithJSON(w, http.StatusMultipleChoices, struct {
+				Error      string          `json:"error"`
+				Candidates []rss.Candidate `json:"candidates"`
+			}{"the page links to several feeds, subscribe to one of them", candidates})
+			return
+		}
+		feedFollow, err = cfg.subscribe(user.ID, url, rssData)
+	case req.FeedId != 0:
+		var feed database.Feed
+		feed, err = cfg.DB.GetFeedById(cfg.ctx, req.FeedId)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "feed not found")
+			return
+		}
+		if err == nil {
+			feedFollow, err = cfg.follow(user.ID, feed, false)
+		}
+	default:
+		respondWithError(w, http.StatusBadRequest, "feed_id or url is required")
+		return
+	}
+	if errors.Is(err, errAlreadyFollowing) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
 	}
-	err := json.NewDecoder(r.Body).Decode(&reqFeedFollow)
 	if err != nil {
-		badRequestHandler(w)
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
-	_, err = cfg.DB.GetFeedFollows(cfg.ctx, database.GetFeedFollowsParams{
+	respondWithJSON(w, http.StatusCreated, feedFollow)
+}
+
+// DeleteFeedFollowByUrl unsubscribes from the feed with the url given in the
+// query string.
+func (cfg *APIConfig) DeleteFeedFollowByUrl(w http.ResponseWriter, r *http.Request, user database.User) {
+	url := SanitizeInput(r.URL.Query().Get("url"))
+	if url == "" {
+		respondWithError(w, http.StatusBadRequest, "url is required")
+		return
+	}
+	deleted, err := cfg.DB.DeleteFeedFollowByUrl(cfg.ctx, database.DeleteFeedFollowByUrlParams{
 		UserID: user.ID,
-		FeedID: reqFeedFollow.FeedId,
+		Url:    url,
 	})
-	if err == nil {
-		internalServerErrorHandler(w)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
-	feedFollow, err := cfg.DB.CreateFeedFollows(cfg.ctx, database.CreateFeedFollowsParams{
-		UserID: user.ID,
-		FeedID: reqFeedFollow.FeedId,
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "feed follow not found")
+		return
+	}
+	respondOk(w)
+}
+
+type FeedDetails struct {
+	database.Feed
+	Follow *database.FeedFollow `json:"follow"`
+}
+
+// feedFollowFor returns the user's follow of a feed, or nil when they don't
+// follow it.
+func (cfg *APIConfig) feedFollowFor(userID, feedID int64) (*database.FeedFollow, error) {
+	followID, err := cfg.DB.GetFeedFollows(cfg.ctx, database.GetFeedFollowsParams{
+		UserID: userID,
+		FeedID: feedID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	follow, err := cfg.DB.GetFeedFollowForUser(cfg.ctx, database.GetFeedFollowForUserParams{
+		ID:     followID,
+		UserID: userID,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return &follow, nil
+}
+
+// GetFeed returns a feed the user follows, with their follow settings.
+func (cfg *APIConfig) GetFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedId, err := strconv.ParseInt(r.PathValue("feedId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid feed ID")
+		return
+	}
+	follow, err := cfg.feedFollowFor(user.ID, feedId)
 	if err != nil {
 		log.Print(err)
-		internalServerErrorHandler(w)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
-	respondWithJSON(w, http.StatusCreated, feedFollow)
+	if follow == nil {
+		respondWithError(w, http.StatusNotFound, "feed not followed")
+		return
+	}
+	feed, err := cfg.DB.GetFeedById(cfg.ctx, feedId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, FeedDetails{Feed: feed, Follow: follow})
 }
 
-func (cfg *APIConfig) DeleteFeedFollows(w http.ResponseWriter, r *http.Request) {
+// GetFeedPostsFromUser is the timeline of a single followed feed, in the
+// order chosen in its settings unless the order parameter overrides it.
+func (cfg *APIConfig) GetFeedPostsFromUser(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedId, err := strconv.ParseInt(r.PathValue("feedId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid feed ID")
+		return
+	}
+	follow, err := cfg.feedFollowFor(user.ID, feedId)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if follow == nil {
+		respondWithError(w, http.StatusNotFound, "feed not followed")
+		return
+	}
+	query := r.URL.Query()
+	query.Set("feed", strconv.FormatInt(feedId, 10))
+	if query.Get("order") == "" {
+		query.Set("order", follow.SortOrder)
+	}
+	cfg.respondWithTimeline(w, r, user.ID, query)
+}
+
+func (cfg *APIConfig) DeleteFeedFollows(w http.ResponseWriter, r *http.Request, user database.User) {
 	feedFollowId := r.PathValue("feedFollowId")
-	id, err := strconv.Atoi(feedFollowId)
+	id, err := strconv.ParseInt(feedFollowId, 10, 64)
 	if err != nil {
 		log.Print(err)
-		badRequestHandler(w)
+		respondWithError(w, http.StatusBadRequest, "invalid feed follow ID")
 		return
 	}
-	err = cfg.DB.DeleteFeedFollows(cfg.ctx, int64(id))
+	deleted, err := cfg.DB.DeleteFeedFollows(cfg.ctx, database.DeleteFeedFollowsParams{
+		ID:     id,
+		UserID: user.ID,
+	})
 	if err != nil {
 		log.Print(err)
-		internalServerErrorHandler(w)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "feed follow not found")
 		return
 	}
 	respondOk(w)
 }
 
-func (cfg *APIConfig) UnsubscribeFeed(w http.ResponseWriter, r *http.Request) {
+func (cfg *APIConfig) unsubscribeFeedPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
 	feedFollowId := r.PathValue("feedFollowId")
-	id, err := strconv.Atoi(feedFollowId)
+	id, err := strconv.ParseInt(feedFollowId, 10, 64)
 	if err != nil {
 		log.Print(err)
 		http.Redirect(w, r, "/feeds?error=invalid feed follow ID", http.StatusSeeOther)
 		return
 	}
-	err = cfg.DB.DeleteFeedFollows(cfg.ctx, int64(id))
+	deleted, err := cfg.DB.DeleteFeedFollows(cfg.ctx, database.DeleteFeedFollowsParams{
+		ID:     id,
+		UserID: auth.SessionData.UserID2,
+	})
 	if err != nil {
 		log.Print(err)
 		http.Redirect(w, r, "/feeds?error=failed to unsubscribe from feed", http.StatusSeeOther)
 		return
 	}
+	if deleted == 0 {
+		notFoundHandler(w)
+		return
+	}
+	http.Redirect(w, r, "/feeds", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) UnsubscribeFeed(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.unsubscribeFeedPage)(w, r)
+}
+
+// Orders a subscription's own timeline can be read in.
+const (
+	sortNewest = "newest"
+	sortOldest = "oldest"
+)
+
+// maxCustomNameLength matches the custom_name column.
+const maxCustomNameLength = 255
+
+// feedFollowSettings applies the changed settings to a subscription. A nil
+// field keeps its current value and an empty custom name restores the feed's
+// own title.
+func feedFollowSettings(follow database.FeedFollow, customName *string, hideFromHome *bool, sortOrder *string) (database.UpdateFeedFollowSettingsParams, error) {
+	params := database.UpdateFeedFollowSettingsParams{
+		ID:           follow.ID,
+		UserID:       follow.UserID,
+		CustomName:   follow.CustomName,
+		HideFromHome: follow.HideFromHome,
+		SortOrder:    follow.SortOrder,
+	}
+	if customName != nil {
+		name := strings.TrimSpace(*customName)
+		if len(name) > maxCustomNameLength {
+			return params, errors.New("custom name is too long")
+		}
+		params.CustomName = sql.NullString{String: name, Valid: name != ""}
+	}
+	if hideFromHome != nil {
+		params.HideFromHome = *hideFromHome
+	}
+	if sortOrder != nil {
+		switch *sortOrder {
+		case sortNewest, sortOldest:
+			params.SortOrder = *sortOrder
+		default:
+			return params, errors.New("sort order must be newest or oldest")
+		}
+	}
+	return params, nil
+}
+
+func (cfg *APIConfig) feedFollowSettingsPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	feedFollowId, err := strconv.ParseInt(r.PathValue("feedFollowId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/feeds?error=invalid feed follow ID", http.StatusSeeOther)
+		return
+	}
+	follow, err := cfg.DB.GetFeedFollowForUser(cfg.ctx, database.GetFeedFollowForUserParams{
+		ID:     feedFollowId,
+		UserID: auth.SessionData.UserID2,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		notFoundHandler(w)
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	customName := r.FormValue("custom_name")
+	hideFromHome := r.FormValue("hide_from_home") != ""
+	sortOrder := r.FormValue("sort_order")
+	params, err := feedFollowSettings(follow, &customName, &hideFromHome, &sortOrder)
+	if err != nil {
+		http.Redirect(w, r, "/feeds?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	_, err = cfg.DB.UpdateFeedFollowSettings(cfg.ctx, params)
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/feeds?error=failed to save feed settings", http.StatusSeeOther)
+		return
+	}
 	http.Redirect(w, r, "/feeds", http.StatusSeeOther)
 }
 
+func (cfg *APIConfig) UpdateFeedFollowSettings(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.feedFollowSettingsPage)(w, r)
+}
+
+func (cfg *APIConfig) UpdateFeedFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedFollowId, err := strconv.ParseInt(r.PathValue("feedFollowId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid feed follow ID")
+		return
+	}
+	var req struct {
+		CustomName   *string `json:"custom_name"`
+		HideFromHome *bool   `json:"hide_from_home"`
+		SortOrder    *string `json:"sort_order"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	follow, err := cfg.DB.GetFeedFollowForUser(cfg.ctx, database.GetFeedFollowForUserParams{
+		ID:     feedFollowId,
+		UserID: user.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "feed follow not found")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	params, err := feedFollowSettings(follow, req.CustomName, req.HideFromHome, req.SortOrder)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	follow, err = cfg.DB.UpdateFeedFollowSettings(cfg.ctx, params)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, follow)
+}
+
+// retryFeed re-enables a feed the user follows and fetches it right away.
+func (cfg *APIConfig) retryFeed(userID int64, feedId int64) (database.Feed, error) {
+	_, err := cfg.DB.GetFeedFollows(cfg.ctx, database.GetFeedFollowsParams{
+		UserID: userID,
+		FeedID: feedId,
+	})
+	if err != nil {
+		return database.Feed{}, err
+	}
+	feed, err := cfg.DB.GetFeedById(cfg.ctx, feedId)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	err = cfg.DB.EnableFeed(cfg.ctx, feed.ID)
+	if err != nil {
+		return database.Feed{}, err
+	}
+	cfg.FetchOneFeedSync(feed.ID, feed.Url)
+	return cfg.DB.GetFeedById(cfg.ctx, feed.ID)
+}
+
+func (cfg *APIConfig) retryFeedPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	feedId, err := strconv.ParseInt(r.PathValue("feedId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/feeds?error=invalid feed ID", http.StatusSeeOther)
+		return
+	}
+	feed, err := cfg.retryFeed(auth.SessionData.UserID2, feedId)
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/feeds?error=couldn't retry feed", http.StatusSeeOther)
+		return
+	}
+	if feed.ConsecutiveFailures > 0 {
+		http.Redirect(w, r, "/feeds?error=fetch failed again: "+url.QueryEscape(feed.LastError.String), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/feeds", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) RetryFeed(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.retryFeedPage)(w, r)
+}
+
+func (cfg *APIConfig) RetryFeedFetch(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedId, err := strconv.ParseInt(r.PathValue("feedId"), 10, 64)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, "invalid feed ID")
+		return
+	}
+	feed, err := cfg.retryFeed(user.ID, feedId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "feed not followed")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, feed)
+}
+
 func GetFeedId(r *http.Request) (int64, error) {
 	q := r.URL.Query()
 	fi := q.Get("feedId")
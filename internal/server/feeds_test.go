@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+func TestFeedFollowSettings(t *testing.T) {
+	follow := database.FeedFollow{
+		ID:         3,
+		UserID:     1,
+		CustomName: sql.NullString{String: "Old name", Valid: true},
+		SortOrder:  sortNewest,
+	}
+
+	params, err := feedFollowSettings(follow, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.ID != 3 || params.UserID != 1 || params.CustomName.String != "Old name" || params.SortOrder != sortNewest {
+		t.Fatalf("nil fields should keep current settings, got %+v", params)
+	}
+
+	name, hide, order := "  Jane's blog ", true, sortOldest
+	params, err = feedFollowSettings(follow, &name, &hide, &order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.CustomName != (sql.NullString{String: "Jane's blog", Valid: true}) || !params.HideFromHome || params.SortOrder != sortOldest {
+		t.Fatalf("unexpected settings %+v", params)
+	}
+
+	empty := " "
+	params, err = feedFollowSettings(follow, &empty, nil, nil)
+	if err != nil || params.CustomName.Valid {
+		t.Fatalf("an empty name should clear the custom name, got %+v, %v", params, err)
+	}
+
+	bad := "random"
+	if _, err := feedFollowSettings(follow, nil, nil, &bad); err == nil {
+		t.Error("unknown sort order should fail")
+	}
+	long := strings.Repeat("x", maxCustomNameLength+1)
+	if _, err := feedFollowSettings(follow, &long, nil, nil); err == nil {
+		t.Error("overlong name should fail")
+	}
+}
+
+type feedStore struct {
+	ownershipStore
+	feeds map[int64]database.Feed
+}
+
+func (s *feedStore) GetFeedById(_ context.Context, id int64) (database.Feed, error) {
+	feed, ok := s.feeds[id]
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return feed, nil
+}
+
+func (s *feedStore) CreateFeedFollows(_ context.Context, arg database.CreateFeedFollowsParams) (database.FeedFollow, error) {
+	follow := database.FeedFollow{ID: int64(len(s.follows) + 10), UserID: arg.UserID, FeedID: arg.FeedID, SortOrder: sortNewest}
+	s.follows[follow.ID] = follow
+	return follow, nil
+}
+
+func TestCreateFeedFollows(t *testing.T) {
+	store := &feedStore{
+		ownershipStore: *newOwnershipStore(),
+		feeds:          map[int64]database.Feed{100: {ID: 100}, 200: {ID: 200}},
+	}
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"invalid body", `{`, http.StatusBadRequest},
+		{"no feed", `{}`, http.StatusBadRequest},
+		{"unknown feed", `{"feed_id": 300}`, http.StatusNotFound},
+		{"already following", `{"feed_id": 100}`, http.StatusConflict},
+		{"new follow", `{"feed_id": 200}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/v1/feed_follows", strings.NewReader(tt.body))
+		cfg.CreateFeedFollows(w, r, database.User{ID: ownerID})
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+		if w.Code >= 400 && !strings.Contains(w.Body.String(), `"error"`) {
+			t.Errorf("%s: expected a JSON error body, got %s", tt.name, w.Body.String())
+		}
+	}
+	if _, ok := store.followFor(ownerID, 200); !ok {
+		t.Fatal("the new follow wasn't stored")
+	}
+}
+
+func TestGetFeedRequiresFollow(t *testing.T) {
+	store := &feedStore{
+		ownershipStore: *newOwnershipStore(),
+		feeds:          map[int64]database.Feed{100: {ID: 100, Etag: sql.NullString{String: `"v1"`, Valid: true}}},
+	}
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+
+	for _, tt := range []struct {
+		user int64
+		want int
+	}{
+		{ownerID, http.StatusOK},
+		{strangerID, http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/feeds/100", nil)
+		r.SetPathValue("feedId", "100")
+		cfg.GetFeed(w, r, database.User{ID: tt.user})
+		if w.Code != tt.want {
+			t.Errorf("user %d: got status %d, want %d", tt.user, w.Code, tt.want)
+		}
+		if tt.want == http.StatusNotFound && strings.Contains(w.Body.String(), "v1") {
+			t.Errorf("user %d: fetch state leaked in %s", tt.user, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,478 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+const maxFolderNameLength = 255
+
+type FoldersData struct {
+	BaseData
+	Error   string
+	Folders []database.GetFoldersByUserRow
+}
+
+type FolderPostsData struct {
+	BaseData
+	Folder     database.Folder
+	Posts      []database.GetPostsByUserAndFolderRow
+	UnreadOnly bool
+	Pagination Pagination
+}
+
+// folderName validates a folder name sent by the user.
+func folderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("folder name is required")
+	}
+	if len(name) > maxFolderNameLength {
+		return "", errors.New("folder name is too long")
+	}
+	return name, nil
+}
+
+// isUniqueViolation reports whether err comes from a unique constraint, such
+// as a user creating two folders with the same name.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (cfg *APIConfig) getFolders(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	folders, err := cfg.DB.GetFoldersByUser(cfg.ctx, auth.SessionData.UserID2)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+
+	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/folders.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", FoldersData{
+		BaseData: cfg.baseData(auth),
+		Error:    r.URL.Query().Get("error"),
+		Folders:  folders,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cfg *APIConfig) GetFolders(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.getFolders)(w, r)
+}
+
+func (cfg *APIConfig) getFolderPosts(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/folders", http.StatusSeeOther)
+		return
+	}
+	folder, err := cfg.DB.GetFolderForUser(cfg.ctx, database.GetFolderForUserParams{
+		ID:     folderId,
+		UserID: auth.SessionData.UserID2,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		notFoundHandler(w)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+
+	pageNumber := GetPageNumber(r)
+	limit, offset := GetPageSizeNumber(r)
+	unreadOnly := GetUnreadOnly(r)
+	posts, err := cfg.DB.GetPostsByUserAndFolder(cfg.ctx, database.GetPostsByUserAndFolderParams{
+		UserID:     auth.SessionData.UserID2,
+		FolderID:   folder.ID,
+		UnreadOnly: unreadOnly,
+		PageLimit:  limit + 1,
+		PageOffset: offset,
+	})
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+
+	pag := NewPagination(pageNumber, len(posts), limit)
+	if len(posts) > int(limit) {
+		posts = posts[:limit]
+	}
+
+	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/folder_posts.html", "html/media.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", FolderPostsData{
+		BaseData:   cfg.baseData(auth),
+		Folder:     folder,
+		Posts:      posts,
+		UnreadOnly: unreadOnly,
+		Pagination: pag,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cfg *APIConfig) GetFolderPosts(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.getFolderPosts)(w, r)
+}
+
+func (cfg *APIConfig) createFolderPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	name, err := folderName(r.FormValue("name"))
+	if err != nil {
+		http.Redirect(w, r, "/folders?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	_, err = cfg.DB.CreateFolder(cfg.ctx, database.CreateFolderParams{
+		UserID: auth.SessionData.UserID2,
+		Name:   name,
+	})
+	if isUniqueViolation(err) {
+		http.Redirect(w, r, "/folders?error=a folder with that name already exists", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/folders?error=failed to create folder", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/folders", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) CreateFolder(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.createFolderPage)(w, r)
+}
+
+func (cfg *APIConfig) renameFolderPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/folders?error=invalid folder ID", http.StatusSeeOther)
+		return
+	}
+	name, err := folderName(r.FormValue("name"))
+	if err != nil {
+		http.Redirect(w, r, "/folders?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	_, err = cfg.DB.RenameFolder(cfg.ctx, database.RenameFolderParams{
+		Name:   name,
+		ID:     folderId,
+		UserID: auth.SessionData.UserID2,
+	})
+	if isUniqueViolation(err) {
+		http.Redirect(w, r, "/folders?error=a folder with that name already exists", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/folders?error=failed to rename folder", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/folders", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) RenameFolder(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.renameFolderPage)(w, r)
+}
+
+func (cfg *APIConfig) deleteFolderPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/folders?error=invalid folder ID", http.StatusSeeOther)
+		return
+	}
+	_, err = cfg.DB.DeleteFolder(cfg.ctx, database.DeleteFolderParams{
+		ID:     folderId,
+		UserID: auth.SessionData.UserID2,
+	})
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/folders?error=failed to delete folder", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/folders", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) RemoveFolder(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.deleteFolderPage)(w, r)
+}
+
+func (cfg *APIConfig) addToFolderPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	feedFollowId, err := strconv.ParseInt(r.PathValue("feedFollowId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/feeds?error=invalid feed follow ID", http.StatusSeeOther)
+		return
+	}
+	folderId, err := strconv.ParseInt(r.FormValue("folder_id"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/feeds?error=choose a folder", http.StatusSeeOther)
+		return
+	}
+	_, err = cfg.DB.AddFeedFollowToFolder(cfg.ctx, database.AddFeedFollowToFolderParams{
+		FeedFollowID: feedFollowId,
+		FolderID:     folderId,
+		UserID:       auth.SessionData.UserID2,
+	})
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/feeds?error=failed to add feed to folder", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/feeds", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) AddToFolder(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.addToFolderPage)(w, r)
+}
+
+func (cfg *APIConfig) removeFromFolderPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	feedFollowId, err := strconv.ParseInt(r.PathValue("feedFollowId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/feeds?error=invalid feed follow ID", http.StatusSeeOther)
+		return
+	}
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/feeds?error=invalid folder ID", http.StatusSeeOther)
+		return
+	}
+	_, err = cfg.DB.RemoveFeedFollowFromFolder(cfg.ctx, database.RemoveFeedFollowFromFolderParams{
+		FeedFollowID: feedFollowId,
+		FolderID:     folderId,
+		UserID:       auth.SessionData.UserID2,
+	})
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/feeds?error=failed to remove feed from folder", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/feeds", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) RemoveFromFolder(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.removeFromFolderPage)(w, r)
+}
+
+func (cfg *APIConfig) GetUserFolders(w http.ResponseWriter, r *http.Request, user database.User) {
+	folders, err := cfg.DB.GetFoldersByUser(cfg.ctx, user.ID)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(folders) < 1 {
+		respondWithJSON(w, http.StatusOK, []int{})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, folders)
+}
+
+func (cfg *APIConfig) CreateUserFolder(w http.ResponseWriter, r *http.Request, user database.User) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	name, err := folderName(req.Name)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	folder, err := cfg.DB.CreateFolder(cfg.ctx, database.CreateFolderParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "a folder with that name already exists")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, folder)
+}
+
+func (cfg *APIConfig) UpdateFolder(w http.ResponseWriter, r *http.Request, user database.User) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid folder ID")
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	name, err := folderName(req.Name)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated, err := cfg.DB.RenameFolder(cfg.ctx, database.RenameFolderParams{
+		Name:   name,
+		ID:     folderId,
+		UserID: user.ID,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "a folder with that name already exists")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if updated == 0 {
+		respondWithError(w, http.StatusNotFound, "folder not found")
+		return
+	}
+	respondOk(w)
+}
+
+func (cfg *APIConfig) DeleteFolder(w http.ResponseWriter, r *http.Request, user database.User) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid folder ID")
+		return
+	}
+	deleted, err := cfg.DB.DeleteFolder(cfg.ctx, database.DeleteFolderParams{
+		ID:     folderId,
+		UserID: user.ID,
+	})
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "folder not found")
+		return
+	}
+	respondOk(w)
+}
+
+func (cfg *APIConfig) GetFolderPostsFromUser(w http.ResponseWriter, r *http.Request, user database.User) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid folder ID")
+		return
+	}
+	_, err = cfg.DB.GetFolderForUser(cfg.ctx, database.GetFolderForUserParams{
+		ID:     folderId,
+		UserID: user.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "folder not found")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	limit, offset := GetPageSizeNumber(r)
+	posts, err := cfg.DB.GetPostsByUserAndFolder(cfg.ctx, database.GetPostsByUserAndFolderParams{
+		UserID:     user.ID,
+		FolderID:   folderId,
+		UnreadOnly: GetUnreadOnly(r),
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(posts) < 1 {
+		respondWithJSON(w, http.StatusOK, []int{})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, posts)
+}
+
+func (cfg *APIConfig) AddFolderFeedFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid folder ID")
+		return
+	}
+	var req struct {
+		FeedFollowId int64 `json:"feed_follow_id"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	added, err := cfg.DB.AddFeedFollowToFolder(cfg.ctx, database.AddFeedFollowToFolderParams{
+		FeedFollowID: req.FeedFollowId,
+		FolderID:     folderId,
+		UserID:       user.ID,
+	})
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if added == 0 {
+		respondWithError(w, http.StatusNotFound, "folder or feed follow not found")
+		return
+	}
+	respondOk(w)
+}
+
+func (cfg *APIConfig) RemoveFolderFeedFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	folderId, err := strconv.ParseInt(r.PathValue("folderId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid folder ID")
+		return
+	}
+	feedFollowId, err := strconv.ParseInt(r.PathValue("feedFollowId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid feed follow ID")
+		return
+	}
+	removed, err := cfg.DB.RemoveFeedFollowFromFolder(cfg.ctx, database.RemoveFeedFollowFromFolderParams{
+		FeedFollowID: feedFollowId,
+		FolderID:     folderId,
+		UserID:       user.ID,
+	})
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, http.StatusNotFound, "folder or feed follow not found")
+		return
+	}
+	respondOk(w)
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFolderName(t *testing.T) {
+	name, err := folderName("  Tech news ")
+	if err != nil || name != "Tech news" {
+		t.Fatalf("got %q, %v", name, err)
+	}
+	for _, bad := range []string{"", "   ", strings.Repeat("a", maxFolderNameLength+1)} {
+		if _, err := folderName(bad); err == nil {
+			t.Errorf("folderName(%q) should fail", bad)
+		}
+	}
+}
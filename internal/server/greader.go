@@ -0,0 +1,707 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odin-software/nyusu/internal/database"
+	"github.com/odin-software/nyusu/internal/sanitize"
+)
+
+// Stream and tag IDs of the Google Reader API. Clients may send user/<id>/
+// instead of user/-/, see greaderStreamID.
+const (
+	greaderReadingList = "user/-/state/com.google/reading-list"
+	greaderRead        = "user/-/state/com.google/read"
+	greaderStarred     = "user/-/state/com.google/starred"
+	greaderKeptUnread  = "user/-/state/com.google/kept-unread"
+	greaderFeedPrefix  = "feed/"
+	greaderLabelPrefix = "user/-/label/"
+	greaderItemPrefix  = "tag:google.com,2005:reader/item/"
+)
+
+// Page sizes of the stream endpoints. Clients ask for thousands of item IDs
+// when they sync unread and starred state, but fetch contents in batches.
+const (
+	greaderDefaultItems = 20
+	greaderMaxItemIDs   = 10000
+	greaderMaxItems     = 1000
+)
+
+// greaderEditToken is handed out by /token. The edit token guards cookie
+// sessions against CSRF in Google Reader; requests here authenticate with a
+// header, so the token clients send back isn't checked.
+const greaderEditToken = "nyusu"
+
+var greaderUserPrefix = regexp.MustCompile(`^user/\d+/`)
+
+type greaderCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+type greaderSubscription struct {
+	ID            string            `json:"id"`
+	Title         string            `json:"title"`
+	Categories    []greaderCategory `json:"categories"`
+	Url           string            `json:"url"`
+	HtmlUrl       string            `json:"htmlUrl"`
+	IconUrl       string            `json:"iconUrl"`
+	FirstItemMsec string            `json:"firstitemmsec"`
+}
+
+type greaderUnreadCount struct {
+	ID                      string `json:"id"`
+	Count                   int64  `json:"count"`
+	NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+	newest                  int64
+}
+
+type greaderItemRef struct {
+	ID            string `json:"id"`
+	TimestampUsec string `json:"timestampUsec"`
+}
+
+type greaderLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+type greaderOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HtmlUrl  string `json:"htmlUrl"`
+}
+
+type greaderItem struct {
+	ID            string         `json:"id"`
+	CrawlTimeMsec string         `json:"crawlTimeMsec"`
+	TimestampUsec string         `json:"timestampUsec"`
+	Published     int64          `json:"published"`
+	Updated       int64          `json:"updated"`
+	Title         string         `json:"title"`
+	Author        string         `json:"author,omitempty"`
+	Canonical     []greaderLink  `json:"canonical"`
+	Alternate     []greaderLink  `json:"alternate"`
+	Summary       greaderContent `json:"summary"`
+	Categories    []string       `json:"categories"`
+	Origin        greaderOrigin  `json:"origin"`
+	Enclosure     []greaderLink  `json:"enclosure,omitempty"`
+}
+
+type greaderStreamContents struct {
+	ID           string        `json:"id"`
+	Updated      int64         `json:"updated"`
+	Items        []greaderItem `json:"items"`
+	Continuation string        `json:"continuation,omitempty"`
+}
+
+// greaderStreamID rewrites user/<id>/ stream and tag IDs to user/-/.
+func greaderStreamID(id string) string {
+	return greaderUserPrefix.ReplaceAllString(strings.TrimSpace(id), "user/-/")
+}
+
+// greaderItemID is the long form of a post's item ID.
+func greaderItemID(postID int64) string {
+	return fmt.Sprintf("%s%016x", greaderItemPrefix, postID)
+}
+
+// parseGReaderItemID accepts the long hexadecimal form of an item ID and the
+// short decimal one used by /stream/items/ids.
+func parseGReaderItemID(id string) (int64, error) {
+	id = strings.TrimSpace(id)
+	if hex, ok := strings.CutPrefix(id, greaderItemPrefix); ok {
+		n, err := strconv.ParseUint(hex, 16, 64)
+		return int64(n), err
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+// greaderToken returns the token of an "Authorization: GoogleLogin auth="
+// header.
+func greaderToken(header string) (string, bool) {
+	scheme, rest, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "GoogleLogin") {
+		return "", false
+	}
+	token, ok := strings.CutPrefix(strings.TrimSpace(rest), "auth=")
+	return token, ok && token != ""
+}
+
+func usec(t time.Time) string {
+	return strconv.FormatInt(t.UnixMicro(), 10)
+}
+
+// greaderStreamParams builds a stream query for the stream ID s and the
+// parameters xt and it (read or starred), n, r=o for oldest first, ot and nt
+// (crawl times in seconds) and c, the continuation returned by the previous
+// page. Label streams are returned by name for the caller to resolve.
+func greaderStreamParams(userID int64, s string, query url.Values, maxItems int32) (database.GetStreamItemIDsParams, string, error) {
+	params := database.GetStreamItemIDsParams{UserID: userID}
+	var label string
+	s = greaderStreamID(s)
+	switch {
+	case s == "" || s == greaderReadingList:
+	case s == greaderStarred:
+		params.StarredOnly = true
+	case s == greaderRead:
+		params.IsRead = sql.NullBool{Bool: true, Valid: true}
+	case strings.HasPrefix(s, greaderFeedPrefix):
+		id, err := strconv.ParseInt(strings.TrimPrefix(s, greaderFeedPrefix), 10, 64)
+		if err != nil {
+			return params, "", errors.New("invalid feed stream")
+		}
+		params.FeedID = sql.NullInt64{Int64: id, Valid: true}
+	case strings.HasPrefix(s, greaderLabelPrefix):
+		label = strings.TrimPrefix(s, greaderLabelPrefix)
+	default:
+		return params, "", fmt.Errorf("unknown stream %q", s)
+	}
+
+	for _, it := range query["it"] {
+		switch greaderStreamID(it) {
+		case greaderRead:
+			params.IsRead = sql.NullBool{Bool: true, Valid: true}
+		case greaderStarred:
+			params.StarredOnly = true
+		}
+	}
+	for _, xt := range query["xt"] {
+		if greaderStreamID(xt) == greaderRead {
+			params.IsRead = sql.NullBool{Bool: false, Valid: true}
+		}
+	}
+
+	params.PageLimit = greaderDefaultItems
+	if n := query.Get("n"); n != "" {
+		limit, err := strconv.ParseInt(n, 10, 32)
+		if err != nil || limit < 1 {
+			return params, "", errors.New("invalid n")
+		}
+		params.PageLimit = int32(min(limit, int64(maxItems)))
+	}
+	if c := query.Get("c"); c != "" {
+		offset, err := strconv.ParseInt(c, 10, 32)
+		if err != nil || offset < 0 {
+			return params, "", errors.New("invalid continuation")
+		}
+		params.PageOffset = int32(offset)
+	}
+	params.OldestFirst = query.Get("r") == "o"
+	if ot := query.Get("ot"); ot != "" {
+		sec, err := strconv.ParseInt(ot, 10, 64)
+		if err != nil {
+			return params, "", errors.New("invalid ot")
+		}
+		params.CrawledAfter = sql.NullTime{Time: time.Unix(sec, 0), Valid: true}
+	}
+	if nt := query.Get("nt"); nt != "" {
+		sec, err := strconv.ParseInt(nt, 10, 64)
+		if err != nil {
+			return params, "", errors.New("invalid nt")
+		}
+		params.CrawledBefore = sql.NullTime{Time: time.Unix(sec, 0), Valid: true}
+	}
+	return params, label, nil
+}
+
+// greaderFolderID finds the folder of a label stream.
+func (cfg *APIConfig) greaderFolderID(userID int64, label string) (int64, error) {
+	folders, err := cfg.DB.GetFoldersByUser(cfg.ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range folders {
+		if f.Name == label {
+			return f.ID, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+// greaderLabels returns the folders of each of the user's subscriptions,
+// keyed by feed follow ID.
+func (cfg *APIConfig) greaderLabels(userID int64) (map[int64][]string, error) {
+	assigned, err := cfg.DB.GetFeedFollowFolders(cfg.ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[int64][]string{}
+	for _, a := range assigned {
+		labels[a.FeedFollowID] = append(labels[a.FeedFollowID], a.Name)
+	}
+	return labels, nil
+}
+
+// greaderStreamIDs runs a stream query, resolving label streams to folders.
+// It returns the posts of the page and the continuation of the next one.
+func (cfg *APIConfig) greaderStreamIDs(userID int64, s string, query url.Values, maxItems int32) ([]database.GetStreamItemIDsRow, string, error) {
+	params, label, err := greaderStreamParams(userID, s, query, maxItems)
+	if err != nil {
+		return nil, "", err
+	}
+	if label != "" {
+		folderID, err := cfg.greaderFolderID(userID, label)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", fmt.Errorf("unknown label %q", label)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		params.FolderID = sql.NullInt64{Int64: folderID, Valid: true}
+	}
+	limit := params.PageLimit
+	params.PageLimit = limit + 1
+	rows, err := cfg.DB.GetStreamItemIDs(cfg.ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+	var continuation string
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		continuation = strconv.FormatInt(int64(params.PageOffset+limit), 10)
+	}
+	return rows, continuation, nil
+}
+
+// greaderItems loads posts in the order of ids, skipping the ones the user
+// can't see.
+func (cfg *APIConfig) greaderItems(userID int64, ids []int64) ([]greaderItem, error) {
+	if len(ids) == 0 {
+		return []greaderItem{}, nil
+	}
+	rows, err := cfg.DB.GetStreamItems(cfg.ctx, database.GetStreamItemsParams{
+		UserID: userID,
+		Ids:    ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	labels, err := cfg.greaderLabels(userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]database.GetStreamItemsRow, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+	items := make([]greaderItem, 0, len(rows))
+	for _, id := range ids {
+		row, ok := byID[id]
+		if !ok {
+			continue
+		}
+		items = append(items, newGReaderItem(row, labels[row.FeedFollowID]))
+	}
+	return items, nil
+}
+
+func newGReaderItem(row database.GetStreamItemsRow, labels []string) greaderItem {
+	content := row.Content.String
+	if content == "" {
+		content = row.Description.String
+	}
+	categories := []string{greaderReadingList}
+	if row.IsRead == 1 {
+		categories = append(categories, greaderRead)
+	}
+	if row.IsBookmarked == 1 {
+		categories = append(categories, greaderStarred)
+	}
+	for _, label := range labels {
+		categories = append(categories, greaderLabelPrefix+label)
+	}
+	item := greaderItem{
+		ID:            greaderItemID(row.ID),
+		CrawlTimeMsec: strconv.FormatInt(row.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: usec(row.PublishedAt),
+		Published:     row.PublishedAt.Unix(),
+		Updated:       row.PublishedAt.Unix(),
+		Title:         row.Title,
+		Author:        row.Author,
+		Canonical:     []greaderLink{{Href: row.Url}},
+		Alternate:     []greaderLink{{Href: row.Url, Type: "text/html"}},
+		Summary:       greaderContent{Direction: "ltr", Content: sanitize.HTML(content, row.Url)},
+		Categories:    categories,
+		Origin: greaderOrigin{
+			StreamID: greaderFeedPrefix + strconv.FormatInt(row.FeedID, 10),
+			Title:    row.FeedName,
+			HtmlUrl:  feedLink(row.FeedLink.String, row.FeedUrl),
+		},
+	}
+	if row.EnclosureUrl.Valid {
+		item.Enclosure = []greaderLink{{Href: row.EnclosureUrl.String, Type: row.EnclosureType.String}}
+	}
+	return item
+}
+
+func greaderOk(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("OK"))
+}
+
+// GReaderClientLogin signs clients in with the account email and an API token
+// as the password. The token is handed back as the Auth value clients send
+// in "Authorization: GoogleLogin auth=".
+func (cfg *APIConfig) GReaderClientLogin(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.FormValue("Email"))
+	token := strings.TrimSpace(r.FormValue("Passwd"))
+	user, _, err := cfg.apiTokenUser(token)
+	if err != nil || !strings.EqualFold(user.Email, email) {
+		http.Error(w, "Error=BadAuthentication", http.StatusForbidden)
+		return
+	}
+	if r.FormValue("output") == "json" {
+		respondWithJSON(w, http.StatusOK, map[string]string{"SID": token, "LSID": token, "Auth": token})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SID=%s\nLSID=%s\nAuth=%s\n", token, token, token)
+}
+
+func (cfg *APIConfig) greaderAuth(scope string, handler AuthHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := greaderToken(r.Header.Get("Authorization"))
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		user, tokenScope, err := cfg.apiTokenUser(token)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if scope == scopeWrite && tokenScope != scopeWrite {
+			http.Error(w, "this API token is read-only", http.StatusForbidden)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+// MiddlewareGReader authenticates Google Reader API requests with the token
+// returned by ClientLogin.
+func (cfg *APIConfig) MiddlewareGReader(handler AuthHandler) http.HandlerFunc {
+	return cfg.greaderAuth(scopeRead, handler)
+}
+
+// MiddlewareGReaderWrite is MiddlewareGReader for requests that change read
+// or starred state, which need a write token. Reads can't be told apart by
+// method as clients POST some of them.
+func (cfg *APIConfig) MiddlewareGReaderWrite(handler AuthHandler) http.HandlerFunc {
+	return cfg.greaderAuth(scopeWrite, handler)
+}
+
+func (cfg *APIConfig) GReaderToken(w http.ResponseWriter, r *http.Request, user database.User) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(greaderEditToken))
+}
+
+func (cfg *APIConfig) GReaderUserInfo(w http.ResponseWriter, r *http.Request, user database.User) {
+	id := strconv.FormatInt(user.ID, 10)
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"userId":        id,
+		"userName":      user.Name,
+		"userProfileId": id,
+		"userEmail":     user.Email,
+	})
+}
+
+func (cfg *APIConfig) GReaderSubscriptions(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := cfg.DB.GetSubscriptionsByUser(cfg.ctx, user.ID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	labels, err := cfg.greaderLabels(user.ID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	subs := make([]greaderSubscription, 0, len(follows))
+	for _, f := range follows {
+		categories := []greaderCategory{}
+		for _, label := range labels[f.ID] {
+			categories = append(categories, greaderCategory{ID: greaderLabelPrefix + label, Label: label})
+		}
+		subs = append(subs, greaderSubscription{
+			ID:            greaderFeedPrefix + strconv.FormatInt(f.FeedID, 10),
+			Title:         f.Name,
+			Categories:    categories,
+			Url:           f.Url,
+			HtmlUrl:       feedLink(f.Link.String, f.Url),
+			FirstItemMsec: strconv.FormatInt(f.CreatedAt.UnixMilli(), 10),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, map[string][]greaderSubscription{"subscriptions": subs})
+}
+
+func (cfg *APIConfig) GReaderTags(w http.ResponseWriter, r *http.Request, user database.User) {
+	folders, err := cfg.DB.GetFoldersByUser(cfg.ctx, user.ID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	tags := []greaderCategory{{ID: greaderStarred}}
+	for _, f := range folders {
+		tags = append(tags, greaderCategory{ID: greaderLabelPrefix + f.Name, Type: "folder"})
+	}
+	respondWithJSON(w, http.StatusOK, map[string][]greaderCategory{"tags": tags})
+}
+
+func (cfg *APIConfig) GReaderUnreadCount(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := cfg.DB.GetFeedFollowsFromUser(cfg.ctx, user.ID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	labels, err := cfg.greaderLabels(user.ID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+
+	counts := make([]greaderUnreadCount, 0, len(follows)+1)
+	totals := map[string]int{}
+	add := func(id string, count int64, newest time.Time) {
+		i, ok := totals[id]
+		if !ok {
+			i = len(counts)
+			totals[id] = i
+			counts = append(counts, greaderUnreadCount{ID: id, NewestItemTimestampUsec: "0"})
+		}
+		counts[i].Count += count
+		if newest.UnixMicro() > counts[i].newest {
+			counts[i].newest = newest.UnixMicro()
+			counts[i].NewestItemTimestampUsec = usec(newest)
+		}
+	}
+	add(greaderReadingList, 0, time.Time{})
+	for _, f := range follows {
+		newest := f.NewestPostAt.Time
+		add(greaderFeedPrefix+strconv.FormatInt(f.FeedID, 10), f.UnreadCount, newest)
+		add(greaderReadingList, f.UnreadCount, newest)
+		for _, label := range labels[f.ID] {
+			add(greaderLabelPrefix+label, f.UnreadCount, newest)
+		}
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"max":          greaderMaxItemIDs,
+		"unreadcounts": counts,
+	})
+}
+
+func (cfg *APIConfig) GReaderStreamItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	rows, continuation, err := cfg.greaderStreamIDs(user.ID, query.Get("s"), query, greaderMaxItemIDs)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	refs := make([]greaderItemRef, 0, len(rows))
+	for _, row := range rows {
+		refs = append(refs, greaderItemRef{
+			ID:            strconv.FormatInt(row.ID, 10),
+			TimestampUsec: usec(row.PublishedAt),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, map[string]any{
+		"itemRefs":     refs,
+		"continuation": continuation,
+	})
+}
+
+func (cfg *APIConfig) GReaderStreamContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	s := r.PathValue("streamId")
+	if s == "" {
+		s = query.Get("s")
+	}
+	rows, continuation, err := cfg.greaderStreamIDs(user.ID, s, query, greaderMaxItems)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	items, err := cfg.greaderItems(user.ID, ids)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	if s == "" {
+		s = greaderReadingList
+	}
+	respondWithJSON(w, http.StatusOK, greaderStreamContents{
+		ID:           greaderStreamID(s),
+		Updated:      time.Now().Unix(),
+		Items:        items,
+		Continuation: continuation,
+	})
+}
+
+// GReaderItemContents returns the posts of the item IDs in i, which clients
+// usually POST.
+func (cfg *APIConfig) GReaderItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	if len(r.Form["i"]) > greaderMaxItems {
+		http.Error(w, "too many items", http.StatusBadRequest)
+		return
+	}
+	ids := make([]int64, 0, len(r.Form["i"]))
+	for _, i := range r.Form["i"] {
+		id, err := parseGReaderItemID(i)
+		if err != nil {
+			http.Error(w, "invalid item ID", http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+	items, err := cfg.greaderItems(user.ID, ids)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, greaderStreamContents{
+		ID:      greaderReadingList,
+		Updated: time.Now().Unix(),
+		Items:   items,
+	})
+}
+
+// GReaderEditTag adds (a) and removes (r) the read and starred tags of the
+// items in i, which map to read posts and bookmarks. Other tags are ignored.
+func (cfg *APIConfig) GReaderEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	if len(r.Form["i"]) == 0 {
+		http.Error(w, "no items", http.StatusBadRequest)
+		return
+	}
+	if len(r.Form["i"]) > greaderMaxItems {
+		http.Error(w, "too many items", http.StatusBadRequest)
+		return
+	}
+	ids := make([]int64, 0, len(r.Form["i"]))
+	for _, i := range r.Form["i"] {
+		id, err := parseGReaderItemID(i)
+		if err != nil {
+			http.Error(w, "invalid item ID", http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		for _, tag := range r.Form["a"] {
+			var err error
+			switch greaderStreamID(tag) {
+			case greaderRead:
+				_, err = cfg.DB.MarkPostRead(cfg.ctx, database.MarkPostReadParams{UserID: user.ID, PostID: id})
+			case greaderKeptUnread:
+				err = cfg.DB.MarkPostUnread(cfg.ctx, database.MarkPostUnreadParams{UserID: user.ID, PostID: id})
+			case greaderStarred:
+				_, err = cfg.DB.BookmarkPost(cfg.ctx, database.BookmarkPostParams{UserID: user.ID, PostID: id})
+			}
+			if err != nil {
+				log.Println(err)
+				internalServerErrorHandler(w)
+				return
+			}
+		}
+		for _, tag := range r.Form["r"] {
+			var err error
+			switch greaderStreamID(tag) {
+			case greaderRead:
+				err = cfg.DB.MarkPostUnread(cfg.ctx, database.MarkPostUnreadParams{UserID: user.ID, PostID: id})
+			case greaderStarred:
+				err = cfg.DB.UnbookmarkPost(cfg.ctx, database.UnbookmarkPostParams{UserID: user.ID, PostID: id})
+			}
+			if err != nil {
+				log.Println(err)
+				internalServerErrorHandler(w)
+				return
+			}
+		}
+	}
+	greaderOk(w)
+}
+
+// GReaderMarkAllAsRead marks the posts of the stream s read, up to ts, the
+// timestamp in microseconds of the newest item the client has seen.
+func (cfg *APIConfig) GReaderMarkAllAsRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	params := database.MarkPostsReadParams{UserID: user.ID}
+	s := greaderStreamID(r.FormValue("s"))
+	switch {
+	case s == greaderReadingList:
+	case strings.HasPrefix(s, greaderFeedPrefix):
+		id, err := strconv.ParseInt(strings.TrimPrefix(s, greaderFeedPrefix), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid feed stream", http.StatusBadRequest)
+			return
+		}
+		params.FeedID = sql.NullInt64{Int64: id, Valid: true}
+	case strings.HasPrefix(s, greaderLabelPrefix):
+		folderID, err := cfg.greaderFolderID(user.ID, strings.TrimPrefix(s, greaderLabelPrefix))
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "unknown label", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Println(err)
+			internalServerErrorHandler(w)
+			return
+		}
+		params.FolderID = sql.NullInt64{Int64: folderID, Valid: true}
+	default:
+		http.Error(w, "unknown stream", http.StatusBadRequest)
+		return
+	}
+	if ts := r.FormValue("ts"); ts != "" {
+		micros, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid ts", http.StatusBadRequest)
+			return
+		}
+		// MarkPostsRead is exclusive; the item at ts has been seen too.
+		params.OlderThan = sql.NullTime{Time: time.UnixMicro(micros + 1), Valid: true}
+	}
+
+	_, err := cfg.DB.MarkPostsRead(cfg.ctx, params)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	greaderOk(w)
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+func TestGReaderItemID(t *testing.T) {
+	id := greaderItemID(1000)
+	if id != "tag:google.com,2005:reader/item/00000000000003e8" {
+		t.Fatalf("unexpected item ID %q", id)
+	}
+	for _, in := range []string{id, "1000", " 1000 "} {
+		got, err := parseGReaderItemID(in)
+		if err != nil || got != 1000 {
+			t.Errorf("parseGReaderItemID(%q) = %d, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "abc", greaderItemPrefix + "xyz"} {
+		if _, err := parseGReaderItemID(in); err == nil {
+			t.Errorf("parseGReaderItemID(%q) should fail", in)
+		}
+	}
+}
+
+func TestGReaderStreamParams(t *testing.T) {
+	params, label, err := greaderStreamParams(7, "user/1005/state/com.google/reading-list", url.Values{
+		"xt": {"user/-/state/com.google/read"},
+		"n":  {"50000"},
+		"c":  {"40"},
+		"r":  {"o"},
+		"ot": {"1700000000"},
+	}, greaderMaxItemIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label != "" || params.UserID != 7 || params.FeedID.Valid || params.StarredOnly {
+		t.Errorf("unexpected params %+v", params)
+	}
+	if !params.IsRead.Valid || params.IsRead.Bool {
+		t.Error("xt=read should exclude read posts")
+	}
+	if params.PageLimit != greaderMaxItemIDs || params.PageOffset != 40 || !params.OldestFirst {
+		t.Errorf("unexpected paging %+v", params)
+	}
+	if params.CrawledAfter.Time.Unix() != 1700000000 {
+		t.Errorf("unexpected ot %v", params.CrawledAfter)
+	}
+
+	params, _, err = greaderStreamParams(7, "feed/12", url.Values{"it": {greaderStarred}}, greaderMaxItems)
+	if err != nil || params.FeedID.Int64 != 12 || !params.StarredOnly || params.PageLimit != greaderDefaultItems {
+		t.Errorf("unexpected feed stream params %+v, %v", params, err)
+	}
+	_, label, err = greaderStreamParams(7, "user/-/label/Tech News", url.Values{}, greaderMaxItems)
+	if err != nil || label != "Tech News" {
+		t.Errorf("got label %q, %v", label, err)
+	}
+
+	for _, s := range []string{"feed/http://example.com", "user/-/state/com.google/broadcast", "splice/1"} {
+		if _, _, err := greaderStreamParams(7, s, url.Values{}, greaderMaxItems); err == nil {
+			t.Errorf("stream %q should be rejected", s)
+		}
+	}
+}
+
+func TestGReaderClientLogin(t *testing.T) {
+	store := &tokenStore{tokens: map[string]database.GetApiTokenByHashRow{
+		hashApiToken("nyusu_read"): {ID: 1, Scope: scopeRead, UserID: 7, Email: "reader@example.com"},
+	}}
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+
+	tests := []struct {
+		email, passwd string
+		want          int
+	}{
+		{"Reader@example.com", "nyusu_read", http.StatusOK},
+		{"someone@example.com", "nyusu_read", http.StatusForbidden},
+		{"reader@example.com", "nyusu_guess", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		form := url.Values{"Email": {tt.email}, "Passwd": {tt.passwd}}
+		r := httptest.NewRequest("POST", "/api/greader/accounts/ClientLogin", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		cfg.GReaderClientLogin(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.email, w.Code, tt.want)
+			continue
+		}
+		if tt.want == http.StatusOK && !strings.Contains(w.Body.String(), "Auth=nyusu_read\n") {
+			t.Errorf("unexpected login response %q", w.Body.String())
+		}
+	}
+}
+
+func TestMiddlewareGReader(t *testing.T) {
+	store := &tokenStore{tokens: map[string]database.GetApiTokenByHashRow{
+		hashApiToken("nyusu_read"):  {ID: 1, Scope: scopeRead, UserID: 7},
+		hashApiToken("nyusu_write"): {ID: 2, Scope: scopeWrite, UserID: 7},
+	}}
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+	ok := func(w http.ResponseWriter, r *http.Request, user database.User) {
+		greaderOk(w)
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		auth    string
+		want    int
+	}{
+		{"no credentials", cfg.MiddlewareGReader(ok), "", http.StatusUnauthorized},
+		{"bearer scheme", cfg.MiddlewareGReader(ok), "Bearer nyusu_read", http.StatusUnauthorized},
+		{"unknown token", cfg.MiddlewareGReader(ok), "GoogleLogin auth=nyusu_guess", http.StatusUnauthorized},
+		{"read token reads", cfg.MiddlewareGReader(ok), "GoogleLogin auth=nyusu_read", http.StatusOK},
+		{"read token edits", cfg.MiddlewareGReaderWrite(ok), "GoogleLogin auth=nyusu_read", http.StatusForbidden},
+		{"write token edits", cfg.MiddlewareGReaderWrite(ok), "GoogleLogin auth=nyusu_write", http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/api/greader/reader/api/0/edit-tag", nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		tt.handler(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestGReaderEditTag(t *testing.T) {
+	store := newOwnershipStore()
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+	form := url.Values{
+		"i": {greaderItemID(1000)},
+		"a": {"user/-/state/com.google/read", "user/-/state/com.google/starred"},
+		"T": {greaderEditToken},
+	}
+	r := httptest.NewRequest("POST", "/api/greader/reader/api/0/edit-tag", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	cfg.GReaderEditTag(w, r, database.User{ID: ownerID})
+	if w.Code != http.StatusOK || w.Body.String() != "OK" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if !store.reads[[2]int64{ownerID, 1000}] || !store.bookmarks[[2]int64{ownerID, 1000}] {
+		t.Error("the post should be read and starred")
+	}
+}
+
+func TestGReaderEditTagLimit(t *testing.T) {
+	store := newOwnershipStore()
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+	form := url.Values{"a": {"user/-/state/com.google/read"}, "T": {greaderEditToken}}
+	for i := 0; i <= greaderMaxItems; i++ {
+		form.Add("i", "1000")
+	}
+	r := httptest.NewRequest("POST", "/api/greader/reader/api/0/edit-tag", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	cfg.GReaderEditTag(w, r, database.User{ID: ownerID})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400", w.Code)
+	}
+	if store.reads[[2]int64{ownerID, 1000}] {
+		t.Error("no post should be marked read")
+	}
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/odin-software/nyusu/internal/database"
+	"github.com/odin-software/nyusu/internal/opml"
+	"github.com/odin-software/nyusu/internal/rss"
+)
+
+// maxOpmlSize caps uploaded OPML files.
+const maxOpmlSize = 5 << 20
+
+// Statuses of a single feed in an OPML import report.
+const (
+	importPending    = "pending"
+	importSubscribed = "subscribed"
+	importExisting   = "already_subscribed"
+	importFailed     = "failed"
+)
+
+type ImportData struct {
+	BaseData
+	Import     database.OpmlImport
+	Items      []database.OpmlImportItem
+	Subscribed int
+	Existing   int
+	Failed     int
+	Pending    int
+}
+
+func (cfg *APIConfig) importOpmlPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxOpmlSize)
+	file, _, err := r.FormFile("opml")
+	if err != nil {
+		http.Redirect(w, r, "/add?error=choose an OPML file to import", http.StatusSeeOther)
+		return
+	}
+	defer file.Close()
+
+	subs, err := opml.Parse(file)
+	if errors.Is(err, opml.ErrNoSubscriptions) {
+		http.Redirect(w, r, "/add?error=the OPML file has no feeds", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		http.Redirect(w, r, "/add?error=couldn't read the OPML file", http.StatusSeeOther)
+		return
+	}
+
+	userID := auth.SessionData.UserID2
+	imp, err := cfg.DB.CreateOpmlImport(cfg.ctx, database.CreateOpmlImportParams{
+		UserID: userID,
+		Total:  int32(len(subs)),
+	})
+	if err != nil {
+		log.Print(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	items := make([]database.OpmlImportItem, 0, len(subs))
+	for _, sub := range subs {
+		item, err := cfg.DB.CreateOpmlImportItem(cfg.ctx, database.CreateOpmlImportItemParams{
+			ImportID: imp.ID,
+			Url:      sub.Url,
+			Title:    sub.Title,
+		})
+		if err != nil {
+			log.Print(err)
+			internalServerErrorHandler(w)
+			return
+		}
+		items = append(items, item)
+	}
+
+	go cfg.runOpmlImport(userID, imp.ID, items, subs)
+	http.Redirect(w, r, "/imports/"+strconv.FormatInt(imp.ID, 10), http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) ImportOpml(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.importOpmlPage)(w, r)
+}
+
+// runOpmlImport subscribes the user to every feed of an import, recording
+// the outcome of each one in the import report.
+func (cfg *APIConfig) runOpmlImport(userID int64, importID int64, items []database.OpmlImportItem, subs []opml.Subscription) {
+	sem := make(chan struct{}, maxConcurrentFetches)
+	var wg sync.WaitGroup
+	for i := range subs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(item database.OpmlImportItem, sub opml.Subscription) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			status, feedID, err := cfg.importSubscription(userID, sub)
+			params := database.UpdateOpmlImportItemParams{
+				ID:     item.ID,
+				Status: status,
+				FeedID: sql.NullInt64{Int64: feedID, Valid: feedID != 0},
+			}
+			if err != nil {
+				params.Message = sql.NullString{String: err.Error(), Valid: true}
+			}
+			if err := cfg.DB.UpdateOpmlImportItem(cfg.ctx, params); err != nil {
+				log.Println(err)
+			}
+		}(items[i], subs[i])
+	}
+	wg.Wait()
+
+	if err := cfg.DB.FinishOpmlImport(cfg.ctx, importID); err != nil {
+		log.Println(err)
+	}
+}
+
+// finishInterruptedImports closes the imports a previous run of the server
+// left running. Imports only run in memory, so their pending feeds are
+// reported as failed and can be imported again.
+func (cfg *APIConfig) finishInterruptedImports() {
+	if err := cfg.DB.FailInterruptedOpmlImportItems(cfg.ctx); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := cfg.DB.FinishInterruptedOpmlImports(cfg.ctx); err != nil {
+		log.Println(err)
+	}
+}
+
+// importSubscription follows a single OPML feed, creating the feed when it is
+// new to Nyusu and adding the follow to the feed's folders.
+func (cfg *APIConfig) importSubscription(userID int64, sub opml.Subscription) (string, int64, error) {
+	feed, err := cfg.DB.GetFeedByUrl(cfg.ctx, sub.Url)
+	created := false
+	if err != nil {
+		rssData, err := rss.DataFromFeed(sub.Url)
+		if err != nil {
+			return importFailed, 0, err
+		}
+		feed, err = cfg.createFeed(sub.Url, rssData, userID)
+		if err != nil {
+			return importFailed, 0, err
+		}
+		created = true
+	}
+
+	status := importExisting
+	feedFollowID, err := cfg.DB.GetFeedFollows(cfg.ctx, database.GetFeedFollowsParams{
+		UserID: userID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		follow, err := cfg.DB.CreateFeedFollows(cfg.ctx, database.CreateFeedFollowsParams{
+			UserID: userID,
+			FeedID: feed.ID,
+		})
+		if err != nil {
+			return importFailed, feed.ID, err
+		}
+		feedFollowID = follow.ID
+		status = importSubscribed
+	}
+
+	for _, name := range sub.Folders {
+		name, err := folderName(name)
+		if err != nil {
+			continue
+		}
+		folder, err := cfg.DB.UpsertFolder(cfg.ctx, database.UpsertFolderParams{
+			UserID: userID,
+			Name:   name,
+		})
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		_, err = cfg.DB.AddFeedFollowToFolder(cfg.ctx, database.AddFeedFollowToFolderParams{
+			FeedFollowID: feedFollowID,
+			FolderID:     folder.ID,
+			UserID:       userID,
+		})
+		if err != nil {
+			log.Println(err)
+		}
+	}
+
+	if created {
+		cfg.FetchOneFeedSync(feed.ID, feed.Url)
+	}
+	return status, feed.ID, nil
+}
+
+func (cfg *APIConfig) getOpmlImport(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	importID, err := strconv.ParseInt(r.PathValue("importId"), 10, 64)
+	if err != nil {
+		notFoundHandler(w)
+		return
+	}
+	imp, err := cfg.DB.GetOpmlImportForUser(cfg.ctx, database.GetOpmlImportForUserParams{
+		ID:     importID,
+		UserID: auth.SessionData.UserID2,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		notFoundHandler(w)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	items, err := cfg.DB.GetOpmlImportItems(cfg.ctx, imp.ID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+
+	data := ImportData{
+		BaseData: cfg.baseData(auth),
+		Import:   imp,
+		Items:    items,
+	}
+	for _, item := range items {
+		switch item.Status {
+		case importSubscribed:
+			data.Subscribed++
+		case importExisting:
+			data.Existing++
+		case importFailed:
+			data.Failed++
+		default:
+			data.Pending++
+		}
+	}
+
+	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/import.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cfg *APIConfig) GetOpmlImport(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.getOpmlImport)(w, r)
+}
+
+func (cfg *APIConfig) exportOpml(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	userID := auth.SessionData.UserID2
+	follows, err := cfg.DB.GetFeedFollowsForExport(cfg.ctx, userID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	assigned, err := cfg.DB.GetFeedFollowFolders(cfg.ctx, userID)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	folders := map[int64][]string{}
+	for _, a := range assigned {
+		folders[a.FeedFollowID] = append(folders[a.FeedFollowID], a.Name)
+	}
+
+	subs := make([]opml.Subscription, 0, len(follows))
+	for _, f := range follows {
+		subs = append(subs, opml.Subscription{
+			Url:     f.Url,
+			Title:   f.Name,
+			SiteUrl: f.Link.String,
+			Folders: folders[f.FeedFollowID],
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="nyusu-subscriptions.opml"`)
+	err = opml.Write(w, opml.Build("Nyusu subscriptions", subs, time.Now()))
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (cfg *APIConfig) ExportOpml(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.exportOpml)(w, r)
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+// ownershipStore is an in-memory stand-in for the queries that check who
+// owns a row, following the WHERE clauses in sql/queries.
+type ownershipStore struct {
+	database.Querier
+	follows   map[int64]database.FeedFollow
+	posts     map[int64]int64 // post ID to feed ID
+	bookmarks map[[2]int64]bool
+	reads     map[[2]int64]bool
+}
+
+func newOwnershipStore() *ownershipStore {
+	return &ownershipStore{
+		follows: map[int64]database.FeedFollow{
+			10: {ID: 10, UserID: ownerID, FeedID: 100, SortOrder: sortNewest},
+		},
+		posts:     map[int64]int64{1000: 100},
+		bookmarks: map[[2]int64]bool{},
+		reads:     map[[2]int64]bool{},
+	}
+}
+
+const (
+	ownerID    int64 = 1
+	strangerID int64 = 2
+)
+
+func (s *ownershipStore) followFor(userID, feedID int64) (database.FeedFollow, bool) {
+	for _, f := range s.follows {
+		if f.UserID == userID && f.FeedID == feedID {
+			return f, true
+		}
+	}
+	return database.FeedFollow{}, false
+}
+
+func (s *ownershipStore) DeleteFeedFollows(_ context.Context, arg database.DeleteFeedFollowsParams) (int64, error) {
+	f, ok := s.follows[arg.ID]
+	if !ok || f.UserID != arg.UserID {
+		return 0, nil
+	}
+	delete(s.follows, arg.ID)
+	return 1, nil
+}
+
+func (s *ownershipStore) GetFeedFollowForUser(_ context.Context, arg database.GetFeedFollowForUserParams) (database.FeedFollow, error) {
+	f, ok := s.follows[arg.ID]
+	if !ok || f.UserID != arg.UserID {
+		return database.FeedFollow{}, sql.ErrNoRows
+	}
+	return f, nil
+}
+
+func (s *ownershipStore) UpdateFeedFollowSettings(_ context.Context, arg database.UpdateFeedFollowSettingsParams) (database.FeedFollow, error) {
+	f, ok := s.follows[arg.ID]
+	if !ok || f.UserID != arg.UserID {
+		return database.FeedFollow{}, sql.ErrNoRows
+	}
+	f.CustomName = arg.CustomName
+	f.HideFromHome = arg.HideFromHome
+	f.SortOrder = arg.SortOrder
+	s.follows[arg.ID] = f
+	return f, nil
+}
+
+func (s *ownershipStore) GetFeedFollows(_ context.Context, arg database.GetFeedFollowsParams) (int64, error) {
+	f, ok := s.followFor(arg.UserID, arg.FeedID)
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return f.ID, nil
+}
+
+func (s *ownershipStore) BookmarkPost(_ context.Context, arg database.BookmarkPostParams) (int64, error) {
+	feedID, ok := s.posts[arg.PostID]
+	if !ok {
+		return 0, nil
+	}
+	if _, ok := s.followFor(arg.UserID, feedID); !ok {
+		return 0, nil
+	}
+	s.bookmarks[[2]int64{arg.UserID, arg.PostID}] = true
+	return 1, nil
+}
+
+func (s *ownershipStore) MarkPostRead(_ context.Context, arg database.MarkPostReadParams) (int64, error) {
+	feedID, ok := s.posts[arg.PostID]
+	if !ok {
+		return 0, nil
+	}
+	if _, ok := s.followFor(arg.UserID, feedID); !ok {
+		return 0, nil
+	}
+	s.reads[[2]int64{arg.UserID, arg.PostID}] = true
+	return 1, nil
+}
+
+func ownershipRequest(method, target string, values map[string]string) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	for k, v := range values {
+		r.SetPathValue(k, v)
+	}
+	return r
+}
+
+func TestDeleteFeedFollowOwnership(t *testing.T) {
+	store := newOwnershipStore()
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+	follow := map[string]string{"feedFollowId": "10"}
+
+	w := httptest.NewRecorder()
+	cfg.DeleteFeedFollows(w, ownershipRequest("DELETE", "/v1/feed_follows/10", follow), database.User{ID: strangerID})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("deleting another user's follow got %d, want 404", w.Code)
+	}
+	w = httptest.NewRecorder()
+	auth := AuthResult{IsAuthenticated: true, SessionData: &database.GetSessionByTokenRow{UserID2: strangerID}}
+	cfg.unsubscribeFeedPage(w, ownershipRequest("POST", "/unsubscribe/10", follow), auth)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unsubscribing another user's follow got %d, want 404", w.Code)
+	}
+	if _, ok := store.follows[10]; !ok {
+		t.Fatal("another user removed the follow")
+	}
+
+	w = httptest.NewRecorder()
+	cfg.DeleteFeedFollows(w, ownershipRequest("DELETE", "/v1/feed_follows/10", follow), database.User{ID: ownerID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("deleting own follow got %d, want 200", w.Code)
+	}
+	if _, ok := store.follows[10]; ok {
+		t.Fatal("the owner couldn't remove the follow")
+	}
+}
+
+func TestUpdateFeedFollowOwnership(t *testing.T) {
+	store := newOwnershipStore()
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+
+	auth := AuthResult{IsAuthenticated: true, SessionData: &database.GetSessionByTokenRow{UserID2: strangerID}}
+	r := ownershipRequest("POST", "/feed_follows/10/settings?custom_name=Mine", map[string]string{"feedFollowId": "10"})
+	w := httptest.NewRecorder()
+	cfg.feedFollowSettingsPage(w, r, auth)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("renaming another user's follow got %d, want 404", w.Code)
+	}
+	if store.follows[10].CustomName.Valid {
+		t.Fatal("another user renamed the follow")
+	}
+}
+
+func TestPostActionOwnership(t *testing.T) {
+	store := newOwnershipStore()
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+	post := map[string]string{"postId": "1000"}
+
+	for _, user := range []int64{strangerID, ownerID} {
+		want := http.StatusNotFound
+		if user == ownerID {
+			want = http.StatusOK
+		}
+		w := httptest.NewRecorder()
+		cfg.BookmarkPost(w, ownershipRequest("POST", "/v1/posts/bookmarks/1000", post), database.User{ID: user})
+		if w.Code != want {
+			t.Errorf("user %d bookmarking got %d, want %d", user, w.Code, want)
+		}
+		w = httptest.NewRecorder()
+		cfg.MarkPostRead(w, ownershipRequest("POST", "/v1/posts/reads/1000", post), database.User{ID: user})
+		if w.Code != want {
+			t.Errorf("user %d marking read got %d, want %d", user, w.Code, want)
+		}
+	}
+	if store.bookmarks[[2]int64{strangerID, 1000}] || store.reads[[2]int64{strangerID, 1000}] {
+		t.Fatal("a user acted on a post from a feed they don't follow")
+	}
+}
+
+func TestRetryFeedOwnership(t *testing.T) {
+	cfg := &APIConfig{ctx: context.Background(), DB: newOwnershipStore()}
+	w := httptest.NewRecorder()
+	r := ownershipRequest("POST", "/v1/feeds/100/retry", map[string]string{"feedId": "100"})
+	cfg.RetryFeedFetch(w, r, database.User{ID: strangerID})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("retrying a feed the user doesn't follow got %d, want 404", w.Code)
+	}
+}
+
+type ruleStore struct {
+	database.Querier
+	rules map[int64]int64
+}
+
+func (s *ruleStore) DeleteFilterRule(_ context.Context, arg database.DeleteFilterRuleParams) (int64, error) {
+	if s.rules[arg.ID] != arg.UserID {
+		return 0, nil
+	}
+	delete(s.rules, arg.ID)
+	return 1, nil
+}
+
+func TestDeleteRuleOwnership(t *testing.T) {
+	store := &ruleStore{rules: map[int64]int64{5: ownerID}}
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+	rule := map[string]string{"ruleId": "5"}
+
+	for _, tt := range []struct {
+		user int64
+		want int
+	}{
+		{strangerID, http.StatusNotFound},
+		{ownerID, http.StatusSeeOther},
+		{ownerID, http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		auth := AuthResult{IsAuthenticated: true, SessionData: &database.GetSessionByTokenRow{UserID2: tt.user}}
+		cfg.deleteRulePage(w, ownershipRequest("POST", "/rules/5/delete", rule), auth)
+		if w.Code != tt.want {
+			t.Errorf("user %d: got status %d, want %d", tt.user, w.Code, tt.want)
+		}
+	}
+}
@@ -1,13 +1,98 @@
 package server
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/odin-software/nyusu/internal/database"
 )
 
+// timelineParams builds a timeline from the query parameters feed, folder,
+// unread, bookmarked, from, to and order (newest or oldest). Without a feed
+// or folder it is the home timeline, which leaves out feeds hidden from it.
+func timelineParams(userId int64, query url.Values) (database.GetTimelinePostsParams, error) {
+	params := database.GetTimelinePostsParams{UserID: userId}
+	if feed := query.Get("feed"); feed != "" {
+		id, err := strconv.ParseInt(feed, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid feed")
+		}
+		params.FeedID = sql.NullInt64{Int64: id, Valid: true}
+	}
+	if folder := query.Get("folder"); folder != "" {
+		id, err := strconv.ParseInt(folder, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid folder")
+		}
+		params.FolderID = sql.NullInt64{Int64: id, Valid: true}
+	}
+	unread, err := strconv.ParseBool(query.Get("unread"))
+	params.UnreadOnly = err == nil && unread
+	bookmarked, err := strconv.ParseBool(query.Get("bookmarked"))
+	params.BookmarkedOnly = err == nil && bookmarked
+	switch order := query.Get("order"); order {
+	case "", sortNewest:
+	case sortOldest:
+		params.OldestFirst = true
+	default:
+		return params, fmt.Errorf("unknown order %q", order)
+	}
+	params.PublishedAfter, params.PublishedBefore, err = publishedRange(query)
+	return params, err
+}
+
+func (cfg *APIConfig) respondWithTimeline(w http.ResponseWriter, r *http.Request, userId int64, query url.Values) {
+	params, err := timelineParams(userId, query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.PageLimit, params.PageOffset = GetPageSizeNumber(r)
+	posts, err := cfg.DB.GetTimelinePosts(cfg.ctx, params)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(posts) < 1 {
+		respondWithJSON(w, http.StatusOK, []int{})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, posts)
+}
+
+func (cfg *APIConfig) GetPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.respondWithTimeline(w, r, user.ID, r.URL.Query())
+}
+
+func (cfg *APIConfig) GetPostFromUser(w http.ResponseWriter, r *http.Request, user database.User) {
+	postId, err := strconv.ParseInt(r.PathValue("postId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+	post, err := cfg.DB.GetPostForUser(cfg.ctx, database.GetPostForUserParams{
+		UserID: user.ID,
+		ID:     postId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "post not found")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, post)
+}
+
 func (cfg *APIConfig) GetBookmarkedPosts(w http.ResponseWriter, r *http.Request, user database.User) {
 	limit, offset := GetPageSizeNumber(r)
 	q := r.URL.Query()
@@ -20,7 +105,7 @@ func (cfg *APIConfig) GetBookmarkedPosts(w http.ResponseWriter, r *http.Request,
 		})
 		if err != nil {
 			log.Print(err)
-			internalServerErrorHandler(w)
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
 			return
 		}
 		if len(posts) < 1 {
@@ -36,7 +121,7 @@ func (cfg *APIConfig) GetBookmarkedPosts(w http.ResponseWriter, r *http.Request,
 		})
 		if err != nil {
 			log.Print(err)
-			internalServerErrorHandler(w)
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
 			return
 		}
 		if len(posts) < 1 {
@@ -52,16 +137,20 @@ func (cfg *APIConfig) BookmarkPost(w http.ResponseWriter, r *http.Request, user
 	id, err := strconv.ParseInt(postId, 10, 64)
 	if err != nil {
 		log.Print(err)
-		badRequestHandler(w)
+		respondWithError(w, http.StatusBadRequest, "invalid post ID")
 		return
 	}
-	err = cfg.DB.BookmarkPost(cfg.ctx, database.BookmarkPostParams{
+	updated, err := cfg.DB.BookmarkPost(cfg.ctx, database.BookmarkPostParams{
 		UserID: user.ID,
 		PostID: id,
 	})
 	if err != nil {
 		log.Println(err)
-		internalServerErrorHandler(w)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if updated == 0 {
+		respondWithError(w, http.StatusNotFound, "post not found")
 		return
 	}
 	respondOk(w)
@@ -72,7 +161,7 @@ func (cfg *APIConfig) UnbookmarkPost(w http.ResponseWriter, r *http.Request, use
 	id, err := strconv.ParseInt(postId, 10, 64)
 	if err != nil {
 		log.Print(err)
-		badRequestHandler(w)
+		respondWithError(w, http.StatusBadRequest, "invalid post ID")
 		return
 	}
 	err = cfg.DB.UnbookmarkPost(cfg.ctx, database.UnbookmarkPostParams{
@@ -81,8 +170,168 @@ func (cfg *APIConfig) UnbookmarkPost(w http.ResponseWriter, r *http.Request, use
 	})
 	if err != nil {
 		log.Println(err)
-		internalServerErrorHandler(w)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondOk(w)
+}
+
+func (cfg *APIConfig) MarkPostRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	postId := r.PathValue("postId")
+	id, err := strconv.ParseInt(postId, 10, 64)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+	updated, err := cfg.DB.MarkPostRead(cfg.ctx, database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: id,
+	})
+	if err != nil {
+		log.Println(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if updated == 0 {
+		respondWithError(w, http.StatusNotFound, "post not found")
+		return
+	}
+	respondOk(w)
+}
+
+func (cfg *APIConfig) MarkPostUnread(w http.ResponseWriter, r *http.Request, user database.User) {
+	postId := r.PathValue("postId")
+	id, err := strconv.ParseInt(postId, 10, 64)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+	err = cfg.DB.MarkPostUnread(cfg.ctx, database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: id,
+	})
+	if err != nil {
+		log.Println(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	respondOk(w)
 }
+
+// markReadParams builds a bulk mark-read for scope "all", "feed" (which
+// needs feedId) or "folder" (which needs folderId), limited to posts
+// published before olderThan when it is set.
+func markReadParams(userId int64, scope, feedId, folderId, olderThan string) (database.MarkPostsReadParams, error) {
+	params := database.MarkPostsReadParams{UserID: userId}
+	switch scope {
+	case "", "all":
+	case "feed":
+		id, err := strconv.ParseInt(feedId, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid feed_id")
+		}
+		params.FeedID = sql.NullInt64{Int64: id, Valid: true}
+	case "folder":
+		id, err := strconv.ParseInt(folderId, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid folder_id")
+		}
+		params.FolderID = sql.NullInt64{Int64: id, Valid: true}
+	default:
+		return params, fmt.Errorf("unknown scope %q", scope)
+	}
+	if olderThan != "" {
+		t, err := ParseTime(olderThan)
+		if err != nil {
+			return params, errors.New("invalid older_than")
+		}
+		params.OlderThan = sql.NullTime{Time: t, Valid: true}
+	}
+	return params, nil
+}
+
+func (cfg *APIConfig) markAllReadPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	scope := r.FormValue("scope")
+	feedId := r.FormValue("feed_id")
+	folderId := r.FormValue("folder_id")
+	params, err := markReadParams(auth.SessionData.UserID2, scope, feedId, folderId, r.FormValue("older_than"))
+	if err != nil {
+		log.Print(err)
+		badRequestHandler(w)
+		return
+	}
+	_, err = cfg.DB.MarkPostsRead(cfg.ctx, params)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	if params.FeedID.Valid {
+		http.Redirect(w, r, "/feeds/"+feedId, http.StatusSeeOther)
+		return
+	}
+	if params.FolderID.Valid {
+		http.Redirect(w, r, "/folders/"+folderId, http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.markAllReadPage)(w, r)
+}
+
+func (cfg *APIConfig) MarkPostsRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	var req struct {
+		Scope     string `json:"scope"`
+		FeedId    int64  `json:"feed_id,omitempty"`
+		FolderId  int64  `json:"folder_id,omitempty"`
+		OlderThan string `json:"older_than,omitempty"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	params, err := markReadParams(user.ID, req.Scope, strconv.FormatInt(req.FeedId, 10), strconv.FormatInt(req.FolderId, 10), req.OlderThan)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	marked, err := cfg.DB.MarkPostsRead(cfg.ctx, params)
+	if err != nil {
+		log.Println(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, struct {
+		Marked int64 `json:"marked"`
+	}{Marked: marked})
+}
+
+func (cfg *APIConfig) GetPostEnclosures(w http.ResponseWriter, r *http.Request, user database.User) {
+	postId := r.PathValue("postId")
+	id, err := strconv.ParseInt(postId, 10, 64)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+	enclosures, err := cfg.DB.GetPostEnclosuresByUser(cfg.ctx, database.GetPostEnclosuresByUserParams{
+		PostID: id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		log.Println(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(enclosures) < 1 {
+		respondWithJSON(w, http.StatusOK, []int{})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, enclosures)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestMarkReadParams(t *testing.T) {
+	params, err := markReadParams(1, "all", "", "", "")
+	if err != nil || params.UserID != 1 || params.FeedID.Valid || params.OlderThan.Valid {
+		t.Fatalf("unexpected all scope %+v, %v", params, err)
+	}
+
+	params, err = markReadParams(1, "feed", "7", "", "2024-07-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !params.FeedID.Valid || params.FeedID.Int64 != 7 {
+		t.Fatalf("unexpected feed %+v", params.FeedID)
+	}
+	if !params.OlderThan.Valid || !params.OlderThan.Time.Equal(time.Date(2024, time.July, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected older_than %+v", params.OlderThan)
+	}
+
+	params, err = markReadParams(1, "folder", "", "3", "")
+	if err != nil || !params.FolderID.Valid || params.FolderID.Int64 != 3 || params.FeedID.Valid {
+		t.Fatalf("unexpected folder scope %+v, %v", params, err)
+	}
+
+	for _, tt := range []struct{ scope, feed, folder, older string }{
+		{"feed", "", "", ""},
+		{"feed", "abc", "", ""},
+		{"folder", "", "", ""},
+		{"everything", "", "", ""},
+		{"all", "", "", "last week"},
+	} {
+		if _, err := markReadParams(1, tt.scope, tt.feed, tt.folder, tt.older); err == nil {
+			t.Errorf("markReadParams(%q, %q, %q, %q) should fail", tt.scope, tt.feed, tt.folder, tt.older)
+		}
+	}
+}
+
+func TestTimelineParams(t *testing.T) {
+	params, err := timelineParams(1, url.Values{})
+	if err != nil || params.UserID != 1 || params.FeedID.Valid || params.FolderID.Valid || params.UnreadOnly || params.OldestFirst {
+		t.Fatalf("unexpected home timeline %+v, %v", params, err)
+	}
+
+	params, err = timelineParams(1, url.Values{
+		"feed":       {"7"},
+		"unread":     {"1"},
+		"bookmarked": {"true"},
+		"order":      {"oldest"},
+		"from":       {"2024-07-01"},
+		"to":         {"2024-07-12"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !params.FeedID.Valid || params.FeedID.Int64 != 7 || !params.UnreadOnly || !params.BookmarkedOnly || !params.OldestFirst {
+		t.Fatalf("unexpected filters %+v", params)
+	}
+	if !params.PublishedBefore.Time.Equal(time.Date(2024, time.July, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("to should cover the whole day, got %v", params.PublishedBefore.Time)
+	}
+
+	for _, query := range []url.Values{
+		{"feed": {"abc"}},
+		{"folder": {"abc"}},
+		{"order": {"random"}},
+		{"from": {"yesterday"}},
+	} {
+		if _, err := timelineParams(1, query); err == nil {
+			t.Errorf("timelineParams(%v) should fail", query)
+		}
+	}
+}
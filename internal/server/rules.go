@@ -0,0 +1,402 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/odin-software/nyusu/internal/database"
+	"github.com/odin-software/nyusu/internal/filter"
+)
+
+// ruleBatchSize is the number of posts loaded at a time when a rule runs
+// over existing posts.
+const ruleBatchSize = 500
+
+type RulesData struct {
+	BaseData
+	Rules   []database.GetFilterRulesByUserRow
+	Feeds   []database.GetFeedFollowsFromUserRow
+	Fields  []string
+	Matches []string
+	Actions []string
+	Error   string
+	Message string
+}
+
+// compiledRule is a filter rule ready to be matched against posts.
+type compiledRule struct {
+	database.FilterRule
+	matcher *filter.Matcher
+}
+
+func compileRule(rule database.FilterRule) (compiledRule, error) {
+	m, err := filter.Compile(rule.Field, rule.MatchType, rule.Pattern)
+	return compiledRule{FilterRule: rule, matcher: m}, err
+}
+
+// ruleParams validates a rule sent by the user. feedId is optional and
+// scopes the rule to a feed the user follows.
+func (cfg *APIConfig) ruleParams(userId int64, feedId, field, match, pattern, action string) (database.CreateFilterRuleParams, error) {
+	params := database.CreateFilterRuleParams{
+		UserID:    userId,
+		Field:     field,
+		MatchType: match,
+		Pattern:   strings.TrimSpace(pattern),
+		Action:    action,
+	}
+	if _, err := filter.Compile(field, match, pattern); err != nil {
+		return params, err
+	}
+	if !filter.ValidAction(action) {
+		return params, errors.New("unknown action")
+	}
+	if feedId != "" && feedId != "0" {
+		id, err := strconv.ParseInt(feedId, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid feed")
+		}
+		_, err = cfg.DB.GetFeedFollows(cfg.ctx, database.GetFeedFollowsParams{
+			UserID: userId,
+			FeedID: id,
+		})
+		if err != nil {
+			return params, errors.New("you don't follow that feed")
+		}
+		params.FeedID = sql.NullInt64{Int64: id, Valid: true}
+	}
+	return params, nil
+}
+
+// feedRules loads the rules of every user following a feed.
+func (cfg *APIConfig) feedRules(feedId int64) []compiledRule {
+	rules, err := cfg.DB.GetFilterRulesForFeed(cfg.ctx, feedId)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			log.Printf("Skipping filter rule %d: %s", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled
+}
+
+// applyRules runs the rules of a feed's followers against a new post.
+func (cfg *APIConfig) applyRules(rules []compiledRule, postId int64, post filter.Post) {
+	for _, rule := range rules {
+		if rule.matcher.Match(post) {
+			if err := cfg.applyRuleAction(rule.FilterRule, postId); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+func (cfg *APIConfig) applyRuleAction(rule database.FilterRule, postId int64) error {
+	switch rule.Action {
+	case filter.ActionHide:
+		return cfg.DB.HidePost(cfg.ctx, database.HidePostParams{
+			UserID: rule.UserID,
+			PostID: postId,
+			RuleID: rule.ID,
+		})
+	case filter.ActionRead:
+		_, err := cfg.DB.MarkPostRead(cfg.ctx, database.MarkPostReadParams{
+			UserID: rule.UserID,
+			PostID: postId,
+		})
+		return err
+	case filter.ActionBookmark:
+		return cfg.DB.AutoBookmarkPost(cfg.ctx, database.AutoBookmarkPostParams{
+			UserID: rule.UserID,
+			PostID: postId,
+		})
+	}
+	return nil
+}
+
+// runRule applies a rule to the posts already stored for its owner and
+// returns how many matched.
+func (cfg *APIConfig) runRule(rule database.FilterRule) (int64, error) {
+	c, err := compileRule(rule)
+	if err != nil {
+		return 0, err
+	}
+	var matched, afterId int64
+	for {
+		posts, err := cfg.DB.GetPostsForRule(cfg.ctx, database.GetPostsForRuleParams{
+			UserID:    rule.UserID,
+			FeedID:    rule.FeedID,
+			AfterID:   afterId,
+			PageLimit: ruleBatchSize,
+		})
+		if err != nil {
+			return matched, err
+		}
+		for _, p := range posts {
+			post := filter.Post{
+				Title:   p.Title,
+				Author:  p.Author,
+				Url:     p.Url,
+				Content: p.Body,
+			}
+			if p.Categories != "" {
+				post.Categories = strings.Split(p.Categories, "\n")
+			}
+			if c.matcher.Match(post) {
+				if err := cfg.applyRuleAction(rule, p.ID); err != nil {
+					return matched, err
+				}
+				matched++
+			}
+			afterId = p.ID
+		}
+		if len(posts) < ruleBatchSize {
+			return matched, nil
+		}
+	}
+}
+
+func (cfg *APIConfig) getRules(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	userId := auth.SessionData.UserID2
+	rules, err := cfg.DB.GetFilterRulesByUser(cfg.ctx, userId)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	feeds, err := cfg.DB.GetFeedFollowsFromUser(cfg.ctx, userId)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+
+	query := r.URL.Query()
+	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/rules.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", RulesData{
+		BaseData: cfg.baseData(auth),
+		Rules:    rules,
+		Feeds:    feeds,
+		Fields:   filter.Fields,
+		Matches:  filter.Matches,
+		Actions:  filter.Actions,
+		Error:    query.Get("error"),
+		Message:  query.Get("message"),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cfg *APIConfig) GetRules(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.getRules)(w, r)
+}
+
+func (cfg *APIConfig) createRulePage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	params, err := cfg.ruleParams(auth.SessionData.UserID2, r.FormValue("feed_id"), r.FormValue("field"),
+		r.FormValue("match_type"), r.FormValue("pattern"), r.FormValue("action"))
+	if err != nil {
+		http.Redirect(w, r, "/rules?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	rule, err := cfg.DB.CreateFilterRule(cfg.ctx, params)
+	if err != nil {
+		log.Println(err)
+		http.Redirect(w, r, "/rules?error=failed to create rule", http.StatusSeeOther)
+		return
+	}
+	if r.FormValue("apply_existing") == "" {
+		http.Redirect(w, r, "/rules", http.StatusSeeOther)
+		return
+	}
+	matched, err := cfg.runRule(rule)
+	if err != nil {
+		log.Println(err)
+		http.Redirect(w, r, "/rules?error=failed to apply rule to existing posts", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/rules?message="+strconv.FormatInt(matched, 10)+" existing posts matched", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) CreateRule(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.createRulePage)(w, r)
+}
+
+func (cfg *APIConfig) applyRulePage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	ruleId, err := strconv.ParseInt(r.PathValue("ruleId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/rules?error=invalid rule ID", http.StatusSeeOther)
+		return
+	}
+	rule, err := cfg.DB.GetFilterRuleForUser(cfg.ctx, database.GetFilterRuleForUserParams{
+		ID:     ruleId,
+		UserID: auth.SessionData.UserID2,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		notFoundHandler(w)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	matched, err := cfg.runRule(rule)
+	if err != nil {
+		log.Println(err)
+		http.Redirect(w, r, "/rules?error=failed to apply rule", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/rules?message="+strconv.FormatInt(matched, 10)+" posts matched", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) ApplyRule(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.applyRulePage)(w, r)
+}
+
+func (cfg *APIConfig) deleteRulePage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	ruleId, err := strconv.ParseInt(r.PathValue("ruleId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/rules?error=invalid rule ID", http.StatusSeeOther)
+		return
+	}
+	deleted, err := cfg.DB.DeleteFilterRule(cfg.ctx, database.DeleteFilterRuleParams{
+		ID:     ruleId,
+		UserID: auth.SessionData.UserID2,
+	})
+	if err != nil {
+		log.Println(err)
+		http.Redirect(w, r, "/rules?error=failed to delete rule", http.StatusSeeOther)
+		return
+	}
+	if deleted == 0 {
+		notFoundHandler(w)
+		return
+	}
+	http.Redirect(w, r, "/rules", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) RemoveRule(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.deleteRulePage)(w, r)
+}
+
+func (cfg *APIConfig) GetUserRules(w http.ResponseWriter, r *http.Request, user database.User) {
+	rules, err := cfg.DB.GetFilterRulesByUser(cfg.ctx, user.ID)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(rules) < 1 {
+		respondWithJSON(w, http.StatusOK, []int{})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, rules)
+}
+
+func (cfg *APIConfig) CreateUserRule(w http.ResponseWriter, r *http.Request, user database.User) {
+	var req struct {
+		FeedID        int64  `json:"feed_id"`
+		Field         string `json:"field"`
+		MatchType     string `json:"match_type"`
+		Pattern       string `json:"pattern"`
+		Action        string `json:"action"`
+		ApplyExisting bool   `json:"apply_existing"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	params, err := cfg.ruleParams(user.ID, strconv.FormatInt(req.FeedID, 10), req.Field, req.MatchType, req.Pattern, req.Action)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rule, err := cfg.DB.CreateFilterRule(cfg.ctx, params)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	var matched int64
+	if req.ApplyExisting {
+		matched, err = cfg.runRule(rule)
+		if err != nil {
+			log.Print(err)
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+	}
+	respondWithJSON(w, http.StatusCreated, struct {
+		database.FilterRule
+		Matched int64 `json:"matched"`
+	}{rule, matched})
+}
+
+func (cfg *APIConfig) DeleteUserRule(w http.ResponseWriter, r *http.Request, user database.User) {
+	ruleId, err := strconv.ParseInt(r.PathValue("ruleId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid rule ID")
+		return
+	}
+	deleted, err := cfg.DB.DeleteFilterRule(cfg.ctx, database.DeleteFilterRuleParams{
+		ID:     ruleId,
+		UserID: user.ID,
+	})
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "rule not found")
+		return
+	}
+	respondOk(w)
+}
+
+func (cfg *APIConfig) ApplyUserRule(w http.ResponseWriter, r *http.Request, user database.User) {
+	ruleId, err := strconv.ParseInt(r.PathValue("ruleId"), 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid rule ID")
+		return
+	}
+	rule, err := cfg.DB.GetFilterRuleForUser(cfg.ctx, database.GetFilterRuleForUserParams{
+		ID:     ruleId,
+		UserID: user.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "rule not found")
+		return
+	}
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	matched, err := cfg.runRule(rule)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]int64{"matched": matched})
+}
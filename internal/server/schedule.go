@@ -0,0 +1,53 @@
+package server
+
+import "time"
+
+const (
+	MinFetchInterval     = 15 * time.Minute
+	DefaultFetchInterval = time.Hour
+	MaxFetchInterval     = 24 * time.Hour
+
+	// recentPostsWindow is how many of the latest posts are used to estimate
+	// how often a feed publishes.
+	recentPostsWindow int32 = 10
+	// maxConcurrentFetches bounds the number of feeds fetched at once.
+	maxConcurrentFetches = 10
+)
+
+// NextFetchInterval picks how long to wait before polling a feed again. It
+// polls about twice per average gap between recent posts, measured up to now
+// so feeds that went quiet slow down, and never more often than the publisher
+// hint (TTL, sy:updatePeriod or Cache-Control) allows.
+func NextFetchInterval(published []time.Time, hint time.Duration, now time.Time) time.Duration {
+	interval := DefaultFetchInterval
+	if len(published) > 0 {
+		oldest := published[len(published)-1]
+		if oldest.Before(now) {
+			interval = now.Sub(oldest) / time.Duration(len(published)) / 2
+		}
+	}
+	if hint > interval {
+		interval = hint
+	}
+	return clampFetchInterval(interval)
+}
+
+// FailureBackoff returns the delay before retrying a feed that has failed
+// the given number of times in a row, doubling with every failure.
+func FailureBackoff(failures int32) time.Duration {
+	d := MinFetchInterval
+	for i := int32(1); i < failures && d < MaxFetchInterval; i++ {
+		d *= 2
+	}
+	return clampFetchInterval(d)
+}
+
+func clampFetchInterval(d time.Duration) time.Duration {
+	if d < MinFetchInterval {
+		return MinFetchInterval
+	}
+	if d > MaxFetchInterval {
+		return MaxFetchInterval
+	}
+	return d
+}
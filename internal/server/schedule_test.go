@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+func TestNextFetchInterval(t *testing.T) {
+	now := time.Date(2024, 7, 12, 12, 0, 0, 0, time.UTC)
+	hourly := []time.Time{}
+	for i := 1; i <= 10; i++ {
+		hourly = append(hourly, now.Add(-time.Duration(i)*time.Hour))
+	}
+	yearly := []time.Time{now.AddDate(-1, 0, 0), now.AddDate(-2, 0, 0)}
+
+	cases := []struct {
+		name      string
+		published []time.Time
+		hint      time.Duration
+		want      time.Duration
+	}{
+		{"no posts", nil, 0, DefaultFetchInterval},
+		{"hourly posts", hourly, 0, 30 * time.Minute},
+		{"hint wins", hourly, 2 * time.Hour, 2 * time.Hour},
+		{"quiet feed", yearly, 0, MaxFetchInterval},
+	}
+	for _, c := range cases {
+		if got := NextFetchInterval(c.published, c.hint, now); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestFailureBackoff(t *testing.T) {
+	if got := FailureBackoff(1); got != MinFetchInterval {
+		t.Errorf("first failure: got %s", got)
+	}
+	if got := FailureBackoff(3); got != 4*MinFetchInterval {
+		t.Errorf("third failure: got %s", got)
+	}
+	if got := FailureBackoff(50); got != MaxFetchInterval {
+		t.Errorf("backoff should be capped, got %s", got)
+	}
+}
+
+type fetchStore struct {
+	database.Querier
+	fetched database.MarkFeedFetchedParams
+}
+
+func (s *fetchStore) GetRecentPostDates(context.Context, database.GetRecentPostDatesParams) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (s *fetchStore) MarkFeedFetched(_ context.Context, arg database.MarkFeedFetchedParams) error {
+	s.fetched = arg
+	return nil
+}
+
+func TestFetchFeedKeepsHintOnNotModified(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+	store := &fetchStore{}
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+
+	start := time.Now()
+	cfg.fetchFeed(database.GetDueFeedsRow{ID: 1, Url: srv.URL, UpdateHintSeconds: 6 * 3600})
+	if store.fetched.UpdateHintSeconds != 6*3600 {
+		t.Errorf("the stored hint became %ds", store.fetched.UpdateHintSeconds)
+	}
+	if next := store.fetched.NextFetchAt.Time; next.Before(start.Add(6 * time.Hour)) {
+		t.Errorf("next fetch at %v ignores the publisher's hint", next)
+	}
+}
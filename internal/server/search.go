@@ -0,0 +1,205 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"html"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+// Markers wrapped around matched words by ts_headline in SearchPosts.
+const (
+	snippetStart = "[[["
+	snippetStop  = "]]]"
+)
+
+// SearchResult replaces the raw ts_headline snippet with highlighted HTML.
+type SearchResult struct {
+	database.SearchPostsRow
+	Snippet template.HTML `json:"snippet"`
+}
+
+type SearchData struct {
+	BaseData
+	Query          string
+	FeedID         int64
+	FolderID       int64
+	From           string
+	To             string
+	BookmarkedOnly bool
+	Feeds          []database.GetFeedFollowsFromUserRow
+	Folders        []database.GetFoldersByUserRow
+	Results        []SearchResult
+	Error          string
+	Params         template.URL
+	Pagination     Pagination
+}
+
+// searchParams builds a search from the query parameters q, feed, folder,
+// from, to and bookmarked.
+func searchParams(userId int64, query url.Values) (database.SearchPostsParams, error) {
+	params := database.SearchPostsParams{
+		UserID: userId,
+		Query:  strings.TrimSpace(query.Get("q")),
+	}
+	bookmarked, err := strconv.ParseBool(query.Get("bookmarked"))
+	params.BookmarkedOnly = err == nil && bookmarked
+	if params.Query == "" {
+		return params, errors.New("search terms are required")
+	}
+	if feed := query.Get("feed"); feed != "" {
+		id, err := strconv.ParseInt(feed, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid feed")
+		}
+		params.FeedID = sql.NullInt64{Int64: id, Valid: true}
+	}
+	if folder := query.Get("folder"); folder != "" {
+		id, err := strconv.ParseInt(folder, 10, 64)
+		if err != nil {
+			return params, errors.New("invalid folder")
+		}
+		params.FolderID = sql.NullInt64{Int64: id, Valid: true}
+	}
+	params.PublishedAfter, params.PublishedBefore, err = publishedRange(query)
+	return params, err
+}
+
+// highlightSnippet turns a ts_headline snippet into HTML, escaping the text
+// and wrapping the matched words in <mark>.
+func highlightSnippet(snippet string) template.HTML {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, snippetStart)
+		if start < 0 {
+			break
+		}
+		stop := strings.Index(snippet[start:], snippetStop)
+		if stop < 0 {
+			break
+		}
+		stop += start
+		b.WriteString(escapeSnippet(snippet[:start]))
+		b.WriteString("<mark>" + escapeSnippet(snippet[start+len(snippetStart):stop]) + "</mark>")
+		snippet = snippet[stop+len(snippetStop):]
+	}
+	b.WriteString(escapeSnippet(snippet))
+	return template.HTML(b.String())
+}
+
+// escapeSnippet escapes text taken from stored post HTML, which may already
+// contain entities.
+func escapeSnippet(s string) string {
+	return html.EscapeString(html.UnescapeString(s))
+}
+
+func (cfg *APIConfig) searchPosts(params database.SearchPostsParams) ([]SearchResult, error) {
+	rows, err := cfg.DB.SearchPosts(cfg.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, SearchResult{
+			SearchPostsRow: row,
+			Snippet:        highlightSnippet(row.Snippet),
+		})
+	}
+	return results, nil
+}
+
+func (cfg *APIConfig) getSearch(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	userId := auth.SessionData.UserID2
+	query := r.URL.Query()
+	data := SearchData{
+		BaseData: cfg.baseData(auth),
+		Query:    query.Get("q"),
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+	}
+
+	feeds, err := cfg.DB.GetFeedFollowsFromUser(cfg.ctx, userId)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	folders, err := cfg.DB.GetFoldersByUser(cfg.ctx, userId)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	data.Feeds = feeds
+	data.Folders = folders
+
+	if strings.TrimSpace(data.Query) != "" {
+		params, err := searchParams(userId, query)
+		data.FeedID = params.FeedID.Int64
+		data.FolderID = params.FolderID.Int64
+		data.BookmarkedOnly = params.BookmarkedOnly
+		if err != nil {
+			data.Error = err.Error()
+		} else {
+			pageNumber := GetPageNumber(r)
+			limit, offset := GetPageSizeNumber(r)
+			params.PageLimit = limit + 1
+			params.PageOffset = offset
+			results, err := cfg.searchPosts(params)
+			if err != nil {
+				log.Println(err)
+				internalServerErrorHandler(w)
+				return
+			}
+			data.Pagination = NewPagination(pageNumber, len(results), limit)
+			if len(results) > int(limit) {
+				results = results[:limit]
+			}
+			data.Results = results
+		}
+		query.Del("pageNumber")
+		data.Params = template.URL(query.Encode())
+	}
+
+	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/search.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cfg *APIConfig) GetSearch(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.getSearch)(w, r)
+}
+
+func (cfg *APIConfig) SearchPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	params, err := searchParams(user.ID, r.URL.Query())
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.PageLimit, params.PageOffset = GetPageSizeNumber(r)
+	results, err := cfg.searchPosts(params)
+	if err != nil {
+		log.Print(err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(results) < 1 {
+		respondWithJSON(w, http.StatusOK, []int{})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, results)
+}
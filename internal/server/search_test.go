@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSearchParams(t *testing.T) {
+	query := url.Values{
+		"q":          {` "error handling" go `},
+		"feed":       {"4"},
+		"folder":     {"2"},
+		"from":       {"2024-07-01"},
+		"to":         {"2024-07-12"},
+		"bookmarked": {"1"},
+	}
+	params, err := searchParams(1, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Query != `"error handling" go` || params.UserID != 1 {
+		t.Fatalf("unexpected query %+v", params)
+	}
+	if params.FeedID.Int64 != 4 || params.FolderID.Int64 != 2 || !params.BookmarkedOnly {
+		t.Fatalf("unexpected filters %+v", params)
+	}
+	if !params.PublishedAfter.Time.Equal(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected from %v", params.PublishedAfter)
+	}
+	// A bare day includes the whole day.
+	if !params.PublishedBefore.Time.Equal(time.Date(2024, time.July, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected to %v", params.PublishedBefore)
+	}
+
+	for _, q := range []url.Values{
+		{"q": {"  "}},
+		{"q": {"go"}, "feed": {"abc"}},
+		{"q": {"go"}, "from": {"someday"}},
+	} {
+		if _, err := searchParams(1, q); err == nil {
+			t.Errorf("searchParams(%v) should fail", q)
+		}
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"the [[[quick]]] brown [[[fox]]]", "the <mark>quick</mark> brown <mark>fox</mark>"},
+		{"Tom &amp; [[[Jerry]]] <script>", "Tom &amp; <mark>Jerry</mark> &lt;script&gt;"},
+		{"  spread\n  over   lines [[[x", "spread over lines [[[x"},
+	}
+	for _, tt := range tests {
+		if got := string(highlightSnippet(tt.in)); got != tt.want {
+			t.Errorf("highlightSnippet(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
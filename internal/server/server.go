@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/joho/godotenv"
 	"github.com/odin-software/nyusu/internal/database"
+	"github.com/odin-software/nyusu/internal/filter"
 	"github.com/odin-software/nyusu/internal/rss"
+	"github.com/odin-software/nyusu/internal/sanitize"
 	"github.com/pressly/goose/v3"
 	"golang.org/x/oauth2"
 )
@@ -24,6 +28,7 @@ type Environment struct {
 	DBUrl            string
 	Port             string
 	Scrapper         int
+	FeedMaxFailures  int
 	Environment      string
 	ProductionURL    string
 	OIDCIssuerURL    string
@@ -53,7 +58,7 @@ type epsilonResponse struct {
 
 type APIConfig struct {
 	ctx          context.Context
-	DB           *database.Queries
+	DB           database.Querier
 	Env          Environment
 	Branding     Branding
 	OIDCProvider *oidc.Provider
@@ -81,6 +86,11 @@ func NewConfig() APIConfig {
 		scrapper = 20
 	}
 
+	feedMaxFailures, err := strconv.Atoi(configValue(remote.Config, "FEED_MAX_FAILURES", os.Getenv("FEED_MAX_FAILURES")))
+	if err != nil || feedMaxFailures < 1 {
+		feedMaxFailures = 10
+	}
+
 	environment := configValue(remote.Config, "ENVIRONMENT", os.Getenv("ENVIRONMENT"))
 	if environment == "" {
 		environment = "development"
@@ -97,6 +107,7 @@ func NewConfig() APIConfig {
 		DBUrl:            configValue(remote.Config, "DB_URL", os.Getenv("DB_URL")),
 		Port:             fmt.Sprintf(":%s", port),
 		Scrapper:         scrapper,
+		FeedMaxFailures:  feedMaxFailures,
 		Environment:      environment,
 		ProductionURL:    productionURL,
 		OIDCIssuerURL:    configValue(remote.Config, "OIDC_ISSUER_URL", os.Getenv("OIDC_ISSUER_URL")),
@@ -139,7 +150,7 @@ func NewConfig() APIConfig {
 
 	branding := buildBranding(remote.Global)
 
-	return APIConfig{
+	cfg := APIConfig{
 		ctx:          ctx,
 		DB:           dbQueries,
 		Env:          env,
@@ -147,6 +158,8 @@ func NewConfig() APIConfig {
 		OIDCProvider: provider,
 		OAuth2Config: oauth2Config,
 	}
+	cfg.finishInterruptedImports()
+	return cfg
 }
 
 func runMigrations(db *sql.DB) error {
@@ -266,84 +279,170 @@ func (cfg *APIConfig) Err(w http.ResponseWriter, r *http.Request) {
 	internalServerErrorHandler(w)
 }
 
-func (cfg *APIConfig) FetchPastFeeds(limit int) {
+// FetchPastFeeds fetches every feed whose next_fetch_at has come due.
+func (cfg *APIConfig) FetchPastFeeds() {
 	var wg sync.WaitGroup
-	fs, err := cfg.DB.GetNextFeedsToFetch(cfg.ctx, int32(limit))
+	fs, err := cfg.DB.GetDueFeeds(cfg.ctx)
 	if err != nil {
 		log.Println(err)
 		return
 	}
+	sem := make(chan struct{}, maxConcurrentFetches)
 	for _, f := range fs {
 		wg.Add(1)
-		go func(id int64, url string) {
+		go func(f database.GetDueFeedsRow) {
 			defer wg.Done()
-			rss, err := rss.DataFromFeed(url)
-			if err != nil {
-				log.Printf("Failed to fetch RSS feed (ID: %d, URL: %s): %s", id, url, err.Error())
-				return // Skip processing if RSS fetch failed
-			}
-			err = cfg.DB.MarkFeedFetched(cfg.ctx, id)
-			if err != nil {
-				log.Println(err)
-				return
-			}
-			for _, p := range rss.Channel.Items {
-				t, err := ParseTime(p.Published)
-				if err != nil {
-					log.Println(err)
-				}
-				author := p.Author
-				if author == "" {
-					author = p.Creator
-				}
-				_, err = cfg.DB.CreatePost(cfg.ctx, database.CreatePostParams{
-					Title:       p.Title,
-					Url:         p.Url,
-					Author:      author,
-					Description: sql.NullString{String: p.Description, Valid: true},
-					FeedID:      id,
-					PublishedAt: t,
-				})
-				if err != nil {
-					continue
-				}
-			}
-		}(f.ID, f.Url)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			cfg.fetchFeed(f)
+		}(f)
 	}
 	wg.Wait()
-	log.Println("Finished fetching feeds")
+	log.Printf("Finished fetching %d feeds", len(fs))
 }
 
 func (cfg *APIConfig) FetchOneFeedSync(feedId int64, url string) {
-	rss, err := rss.DataFromFeed(url)
+	cfg.fetchFeed(database.GetDueFeedsRow{ID: feedId, Url: url})
+}
+
+// fetchFeed conditionally downloads a feed, stores any new posts and
+// schedules the next fetch. A 304 counts as a successful fetch; failures are
+// recorded on the feed, push the next attempt back exponentially and disable
+// the feed after FeedMaxFailures in a row.
+func (cfg *APIConfig) fetchFeed(f database.GetDueFeedsRow) {
+	res, err := rss.FetchFeed(f.Url, rss.Validators{
+		ETag:         f.Etag.String,
+		LastModified: f.LastModified.String,
+	})
 	if err != nil {
-		log.Printf("Failed to fetch RSS feed (ID: %d, URL: %s): %s", feedId, url, err.Error())
-		return // Skip processing if RSS fetch failed
+		log.Printf("Failed to fetch RSS feed (ID: %d, URL: %s): %s", f.ID, f.Url, err.Error())
+		err = cfg.DB.MarkFeedFailed(cfg.ctx, database.MarkFeedFailedParams{
+			ID:          f.ID,
+			NextFetchAt: sql.NullTime{Time: time.Now().Add(FailureBackoff(f.ConsecutiveFailures + 1)), Valid: true},
+			LastError:   sql.NullString{String: err.Error(), Valid: true},
+			MaxFailures: int32(cfg.Env.FeedMaxFailures),
+		})
+		if err != nil {
+			log.Println(err)
+		}
+		if int(f.ConsecutiveFailures)+1 >= cfg.Env.FeedMaxFailures {
+			log.Printf("Disabled feed (ID: %d, URL: %s) after %d failed fetches", f.ID, f.Url, f.ConsecutiveFailures+1)
+		}
+		return
+	}
+	if !res.NotModified {
+		cfg.storePosts(f.ID, feedLink(res.Feed.Channel.Link, f.Url), res.Feed.Channel.Items)
 	}
-	err = cfg.DB.MarkFeedFetched(cfg.ctx, feedId)
+
+	published, err := cfg.DB.GetRecentPostDates(cfg.ctx, database.GetRecentPostDatesParams{
+		FeedID: f.ID,
+		Limit:  recentPostsWindow,
+	})
 	if err != nil {
 		log.Println(err)
-		return
 	}
-	for _, p := range rss.Channel.Items {
-		t, err := ParseTime(p.Published)
-		if err != nil {
-			log.Println(err)
-		}
+	// A 304 has no body to read the publisher's hint from, so the one stored
+	// with the last full fetch is kept.
+	feedHint := time.Duration(f.UpdateHintSeconds) * time.Second
+	if !res.NotModified {
+		feedHint = res.Feed.UpdateHint()
+	}
+	hint := feedHint
+	if res.MaxAge > hint {
+		hint = res.MaxAge
+	}
+	now := time.Now()
+	err = cfg.DB.MarkFeedFetched(cfg.ctx, database.MarkFeedFetchedParams{
+		ID:                f.ID,
+		Etag:              sql.NullString{String: res.Validators.ETag, Valid: res.Validators.ETag != ""},
+		LastModified:      sql.NullString{String: res.Validators.LastModified, Valid: res.Validators.LastModified != ""},
+		NextFetchAt:       sql.NullTime{Time: now.Add(NextFetchInterval(published, hint, now)), Valid: true},
+		UpdateHintSeconds: int32(feedHint / time.Second),
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// feedLink returns the absolute site link of a feed, falling back to the
+// feed URL when the channel link is missing or relative.
+func feedLink(link, feedUrl string) string {
+	base, err := url.Parse(feedUrl)
+	if err != nil {
+		return feedUrl
+	}
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || link == "" {
+		return feedUrl
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// storePosts saves new items of a feed. Item links are resolved against the
+// feed link, and descriptions and content are sanitized with relative URLs
+// resolved against the item link.
+func (cfg *APIConfig) storePosts(feedId int64, link string, items []rss.Entry) {
+	now := time.Now()
+	base, _ := url.Parse(link)
+	var rules []compiledRule
+	if len(items) > 0 {
+		rules = cfg.feedRules(feedId)
+	}
+	for _, p := range items {
 		author := p.Author
 		if author == "" {
 			author = p.Creator
 		}
-		_, err = cfg.DB.CreatePost(cfg.ctx, database.CreatePostParams{
+		postUrl := strings.TrimSpace(p.Url)
+		if ref, err := url.Parse(postUrl); err == nil && base != nil {
+			postUrl = base.ResolveReference(ref).String()
+		}
+		description := sanitize.HTML(p.Description, postUrl)
+		content := sanitize.HTML(p.FullContent(), postUrl)
+		post, err := cfg.DB.CreatePost(cfg.ctx, database.CreatePostParams{
 			Title:       p.Title,
-			Url:         p.Url,
+			Url:         postUrl,
 			Author:      author,
-			Description: sql.NullString{String: p.Description, Valid: true},
+			Description: sql.NullString{String: description, Valid: true},
+			Content:     sql.NullString{String: content, Valid: content != ""},
 			FeedID:      feedId,
-			PublishedAt: t,
+			PublishedAt: PublishedAt(p, now),
 		})
 		if err != nil {
 			continue
 		}
+		for _, c := range p.Categories {
+			c = strings.TrimSpace(c)
+			if c == "" || len(c) > 255 {
+				continue
+			}
+			err = cfg.DB.CreatePostCategory(cfg.ctx, database.CreatePostCategoryParams{
+				PostID: post.ID,
+				Name:   c,
+			})
+			if err != nil {
+				log.Println(err)
+			}
+		}
+		for _, e := range p.Enclosures {
+			err = cfg.DB.CreatePostEnclosure(cfg.ctx, database.CreatePostEnclosureParams{
+				PostID:       post.ID,
+				Url:          e.Url,
+				MimeType:     e.Type,
+				Length:       e.Length,
+				Duration:     int32(e.Duration),
+				ThumbnailUrl: sql.NullString{String: e.Thumbnail, Valid: e.Thumbnail != ""},
+			})
+			if err != nil {
+				log.Println(err)
+			}
+		}
+		cfg.applyRules(rules, post.ID, filter.Post{
+			Title:      post.Title,
+			Author:     author,
+			Url:        postUrl,
+			Content:    description + " " + content,
+			Categories: p.Categories,
+		})
 	}
 }
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+// Scopes of an API token. Read tokens can only make GET requests.
+const (
+	scopeRead  = "read"
+	scopeWrite = "write"
+)
+
+// apiTokenPrefix makes Nyusu tokens easy to recognise, e.g. by secret
+// scanners.
+const apiTokenPrefix = "nyusu_"
+
+// maxTokenNameLength matches the name column.
+const maxTokenNameLength = 100
+
+type SettingsData struct {
+	BaseData
+	Tokens   []database.GetApiTokensByUserRow
+	NewToken string
+	Error    string
+}
+
+// hashApiToken is what gets stored for a token; the token itself is only
+// shown once, when it is created.
+func hashApiToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiTokenParams validates a new token's name and scope and generates it.
+func apiTokenParams(userID int64, name, scope string) (database.CreateApiTokenParams, string, error) {
+	name = SanitizeInput(name)
+	if name == "" {
+		return database.CreateApiTokenParams{}, "", errors.New("token name is required")
+	}
+	if len(name) > maxTokenNameLength {
+		return database.CreateApiTokenParams{}, "", errors.New("token name is too long")
+	}
+	if scope != scopeRead && scope != scopeWrite {
+		return database.CreateApiTokenParams{}, "", errors.New("unknown token scope")
+	}
+	secret, err := GenerateSecureToken()
+	if err != nil {
+		return database.CreateApiTokenParams{}, "", err
+	}
+	token := apiTokenPrefix + secret
+	return database.CreateApiTokenParams{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashApiToken(token),
+		Scope:     scope,
+	}, token, nil
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	return token, ok && strings.EqualFold(scheme, "Bearer") && token != ""
+}
+
+// apiTokenUser looks up the user of an API token and records that the token
+// was used.
+func (cfg *APIConfig) apiTokenUser(token string) (database.User, string, error) {
+	row, err := cfg.DB.GetApiTokenByHash(cfg.ctx, hashApiToken(token))
+	if err != nil {
+		return database.User{}, "", err
+	}
+	if err := cfg.DB.TouchApiToken(cfg.ctx, row.ID); err != nil {
+		log.Print(err)
+	}
+	user := database.User{
+		ID:        row.UserID,
+		Name:      row.Name,
+		Email:     row.Email,
+		Sub:       row.Sub,
+		CreatedAt: row.UserCreatedAt,
+		UpdatedAt: row.UserUpdatedAt,
+	}
+	return user, row.Scope, nil
+}
+
+func (cfg *APIConfig) renderSettings(w http.ResponseWriter, auth AuthResult, data SettingsData) {
+	tokens, err := cfg.DB.GetApiTokensByUser(cfg.ctx, auth.SessionData.UserID2)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	data.BaseData = cfg.baseData(auth)
+	data.Tokens = tokens
+
+	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/settings.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cfg *APIConfig) getSettings(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	cfg.renderSettings(w, auth, SettingsData{Error: r.URL.Query().Get("error")})
+}
+
+func (cfg *APIConfig) GetSettings(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.getSettings)(w, r)
+}
+
+// createApiTokenPage renders the settings page directly instead of
+// redirecting, so the new token never ends up in a URL.
+func (cfg *APIConfig) createApiTokenPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	params, token, err := apiTokenParams(auth.SessionData.UserID2, r.FormValue("name"), r.FormValue("scope"))
+	if err != nil {
+		cfg.renderSettings(w, auth, SettingsData{Error: err.Error()})
+		return
+	}
+	_, err = cfg.DB.CreateApiToken(cfg.ctx, params)
+	if err != nil {
+		log.Println(err)
+		cfg.renderSettings(w, auth, SettingsData{Error: "failed to create token"})
+		return
+	}
+	cfg.renderSettings(w, auth, SettingsData{NewToken: token})
+}
+
+func (cfg *APIConfig) CreateApiToken(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.createApiTokenPage)(w, r)
+}
+
+func (cfg *APIConfig) revokeApiTokenPage(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	tokenId, err := strconv.ParseInt(r.PathValue("tokenId"), 10, 64)
+	if err != nil {
+		http.Redirect(w, r, "/settings?error=invalid token ID", http.StatusSeeOther)
+		return
+	}
+	deleted, err := cfg.DB.DeleteApiToken(cfg.ctx, database.DeleteApiTokenParams{
+		ID:     tokenId,
+		UserID: auth.SessionData.UserID2,
+	})
+	if err != nil {
+		log.Println(err)
+		http.Redirect(w, r, "/settings?error=failed to revoke token", http.StatusSeeOther)
+		return
+	}
+	if deleted == 0 {
+		notFoundHandler(w)
+		return
+	}
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+func (cfg *APIConfig) RevokeApiToken(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.revokeApiTokenPage)(w, r)
+}
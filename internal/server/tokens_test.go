@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/odin-software/nyusu/internal/database"
+)
+
+type tokenStore struct {
+	database.Querier
+	tokens  map[string]database.GetApiTokenByHashRow
+	touched []int64
+}
+
+func (s *tokenStore) GetApiTokenByHash(_ context.Context, hash string) (database.GetApiTokenByHashRow, error) {
+	row, ok := s.tokens[hash]
+	if !ok {
+		return database.GetApiTokenByHashRow{}, sql.ErrNoRows
+	}
+	return row, nil
+}
+
+func (s *tokenStore) TouchApiToken(_ context.Context, id int64) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func TestApiTokenParams(t *testing.T) {
+	params, token, err := apiTokenParams(7, "  my script ", scopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		t.Errorf("token %q is missing the prefix", token)
+	}
+	if params.UserID != 7 || params.Name != "my script" || params.Scope != scopeRead {
+		t.Errorf("unexpected params %+v", params)
+	}
+	if params.TokenHash == token || params.TokenHash != hashApiToken(token) {
+		t.Error("the token should be stored hashed")
+	}
+
+	for _, tt := range []struct{ name, scope string }{
+		{"", scopeRead},
+		{strings.Repeat("x", maxTokenNameLength+1), scopeRead},
+		{"script", "admin"},
+	} {
+		if _, _, err := apiTokenParams(7, tt.name, tt.scope); err == nil {
+			t.Errorf("apiTokenParams(%q, %q) should fail", tt.name, tt.scope)
+		}
+	}
+}
+
+func TestMiddlewareAuthBearer(t *testing.T) {
+	store := &tokenStore{tokens: map[string]database.GetApiTokenByHashRow{
+		hashApiToken("nyusu_read"):  {ID: 1, Scope: scopeRead, UserID: 7},
+		hashApiToken("nyusu_write"): {ID: 2, Scope: scopeWrite, UserID: 7},
+	}}
+	cfg := &APIConfig{ctx: context.Background(), DB: store}
+	var gotUser int64
+	handler := cfg.MiddlewareAuth(func(w http.ResponseWriter, r *http.Request, user database.User) {
+		gotUser = user.ID
+		respondOk(w)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		auth   string
+		want   int
+	}{
+		{"no credentials", http.MethodGet, "", http.StatusUnauthorized},
+		{"unknown token", http.MethodGet, "Bearer nyusu_guess", http.StatusUnauthorized},
+		{"wrong scheme", http.MethodGet, "Basic nyusu_read", http.StatusUnauthorized},
+		{"read token reads", http.MethodGet, "Bearer nyusu_read", http.StatusOK},
+		{"read token writes", http.MethodDelete, "Bearer nyusu_read", http.StatusForbidden},
+		{"write token writes", http.MethodDelete, "bearer nyusu_write", http.StatusOK},
+	}
+	for _, tt := range tests {
+		gotUser = 0
+		r := httptest.NewRequest(tt.method, "/v1/posts/bookmarks/1", nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+			continue
+		}
+		if tt.want == http.StatusOK {
+			if gotUser != 7 {
+				t.Errorf("%s: handler got user %d", tt.name, gotUser)
+			}
+			continue
+		}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Error == "" {
+			t.Errorf("%s: expected a JSON error, got %v", tt.name, err)
+		}
+	}
+	if len(store.touched) != 3 {
+		t.Errorf("expected 3 token uses to be recorded, got %v", store.touched)
+	}
+}
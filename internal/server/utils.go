@@ -1,25 +1,87 @@
 package server
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"math"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/odin-software/nyusu/internal/database"
+	"github.com/odin-software/nyusu/internal/rss"
 )
 
+// timeFormats are tried in order by ParseTime, after named zones have been
+// replaced with numeric offsets. Day "2" also matches zero padded days.
 var timeFormats = []string{
-	time.RFC1123,
-	time.RFC1123Z,
-	time.RFC3339,
-	time.RFC822,
-	time.RFC822Z,
-	"January _2, 2006",
+	// RFC 822 / 1123 and the variations feeds actually publish.
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 -07:00",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 06 15:04:05 -0700",
+	"Mon, 2 Jan 06 15:04 -0700",
+	"Mon, 2 January 2006 15:04:05 -0700",
+	"Mon 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05",
+	"Mon, 2 Jan 2006",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"Mon Jan 2 15:04:05 -0700 2006",
+	"Mon Jan 2 15:04:05 2006",
+	// ISO 8601 / RFC 3339 variants.
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	// Human readable dates.
+	"January 2, 2006 15:04",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
 }
 
+// timeZones maps the zone abbreviations seen in feeds to their UTC offset
+// in seconds, since time.Parse treats abbreviations it doesn't know as UTC.
+var timeZones = map[string]int{
+	"UT": 0, "UTC": 0, "GMT": 0, "Z": 0, "WET": 0,
+	"EST": -5 * 3600, "EDT": -4 * 3600,
+	"CST": -6 * 3600, "CDT": -5 * 3600,
+	"MST": -7 * 3600, "MDT": -6 * 3600,
+	"PST": -8 * 3600, "PDT": -7 * 3600,
+	"AKST": -9 * 3600, "AKDT": -8 * 3600,
+	"HST":  -10 * 3600,
+	"WEST": 1 * 3600, "BST": 1 * 3600,
+	"CET": 1 * 3600, "CEST": 2 * 3600, "MET": 1 * 3600, "MEST": 2 * 3600,
+	"EET": 2 * 3600, "EEST": 3 * 3600,
+	"MSK": 3 * 3600,
+	"IST": 5*3600 + 1800,
+	"SGT": 8 * 3600, "HKT": 8 * 3600, "AWST": 8 * 3600,
+	"JST": 9 * 3600, "KST": 9 * 3600,
+	"ACST": 9*3600 + 1800, "ACDT": 10*3600 + 1800,
+	"AEST": 10 * 3600, "AEDT": 11 * 3600,
+	"NZST": 12 * 3600, "NZDT": 13 * 3600,
+}
+
+var trailingZone = regexp.MustCompile(`\s\(?([A-Za-z]{1,5})\)?$`)
+
+var trailingOffset = regexp.MustCompile(`[+-]\d{2}:?\d{2}$`)
+
 func internalServerErrorHandler(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusInternalServerError)
 	w.Write([]byte("500 Internal Server Error"))
@@ -35,6 +97,11 @@ func notFoundHandler(w http.ResponseWriter) {
 	w.Write([]byte("404 Not Found"))
 }
 
+func conflictHandler(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusConflict)
+	w.Write([]byte("409 Conflict"))
+}
+
 func respondOk(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusOK)
 }
@@ -51,6 +118,10 @@ func respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Write(data)
 }
 
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]string{"error": message})
+}
+
 const DefaultPageSize int32 = 15
 
 type Pagination struct {
@@ -93,7 +164,54 @@ func GetPageNumber(r *http.Request) int32 {
 	return int32(pageNumber)
 }
 
+// GetUnreadOnly reports whether the request asks for unread posts only via
+// ?unread=1 (or any value strconv.ParseBool accepts as true).
+func GetUnreadOnly(r *http.Request) bool {
+	unread, err := strconv.ParseBool(r.URL.Query().Get("unread"))
+	return err == nil && unread
+}
+
+// publishedRange reads the from and to query parameters of a search or
+// timeline. Dates are inclusive, so a bare day in to covers that whole day.
+func publishedRange(query url.Values) (after sql.NullTime, before sql.NullTime, err error) {
+	if from := query.Get("from"); from != "" {
+		t, err := ParseTime(from)
+		if err != nil {
+			return after, before, errors.New("invalid from date")
+		}
+		after = sql.NullTime{Time: t, Valid: true}
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := ParseTime(to)
+		if err != nil {
+			return after, before, errors.New("invalid to date")
+		}
+		if _, err := time.Parse("2006-01-02", to); err == nil {
+			t = t.AddDate(0, 0, 1)
+		}
+		before = sql.NullTime{Time: t, Valid: true}
+	}
+	return after, before, nil
+}
+
+// ParseTime parses the many date formats found in feeds. Named zones such
+// as EST or CEST are converted to numeric offsets before parsing, and dates
+// without a zone are taken as UTC.
 func ParseTime(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, errors.New("couldn't parse the time value")
+	}
+	if m := trailingZone.FindStringSubmatchIndex(value); m != nil {
+		if trailingOffset.MatchString(value[:m[0]]) {
+			// RFC 2822 allows the zone name as a comment after the offset.
+			value = value[:m[0]]
+		} else {
+			// Abbreviations we don't know are read as UTC, like time.Parse does.
+			offset := timeZones[strings.ToUpper(value[m[2]:m[3]])]
+			value = value[:m[0]] + " " + formatOffset(offset)
+		}
+	}
 	for _, format := range timeFormats {
 		t, err := time.Parse(format, value)
 		if err == nil {
@@ -103,6 +221,32 @@ func ParseTime(value string) (time.Time, error) {
 	return time.Time{}, errors.New("couldn't parse the time value")
 }
 
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// PublishedAt picks the publication date of a feed item: its pubDate or
+// Atom published date, then Atom <updated>, then dc:date, and finally the
+// time it was first seen. Dates in the future are clamped to firstSeen.
+func PublishedAt(p rss.Entry, firstSeen time.Time) time.Time {
+	for _, value := range []string{p.Published, p.Updated, p.Date} {
+		t, err := ParseTime(value)
+		if err != nil {
+			continue
+		}
+		if t.After(firstSeen) {
+			return firstSeen
+		}
+		return t
+	}
+	return firstSeen
+}
+
 type AuthResult struct {
 	IsAuthenticated bool
 	SessionData     *database.GetSessionByTokenRow
@@ -123,6 +267,19 @@ func (cfg *APIConfig) MiddlewareWebAuth(handler WebHandler) http.HandlerFunc {
 			}
 		}
 
+		if result.IsAuthenticated && !safeMethod(r.Method) {
+			token, err := formCSRFToken(w, r)
+			if err != nil {
+				log.Print(err)
+				badRequestHandler(w)
+				return
+			}
+			if !csrfTokenMatches(result.SessionData.CsrfToken, token) {
+				cfg.csrfFailedPage(w, result)
+				return
+			}
+		}
+
 		handler(w, r, result)
 	}
 }
@@ -1,6 +1,11 @@
 package server
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/odin-software/nyusu/internal/rss"
+)
 
 var str = "Fri, 12 Jul 2024 13:00:00 +0200"
 
@@ -10,3 +15,69 @@ func TestParseTime(t *testing.T) {
 		t.Fatal("shouldn't fail")
 	}
 }
+
+func TestParseTimeFormats(t *testing.T) {
+	want := time.Date(2024, time.July, 12, 11, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"Fri, 12 Jul 2024 13:00:00 +0200", want},
+		{"Fri, 12 Jul 2024 07:00:00 EDT", want},
+		{"Fri, 12 Jul 2024 04:00:00 PDT", want},
+		{"Fri, 12 Jul 2024 13:00:00 CEST", want},
+		{"Fri, 12 Jul 2024 04:00:00 -0700 (PDT)", want},
+		{"Fri, 12 Jul 2024 13:00:00 +0200 CEST", want},
+		{"Fri, 12 Jul 2024 11:00:00 GMT", want},
+		{"Fri, 12 Jul 2024 11:00 GMT", want},
+		{"Fri,  12 Jul 2024 11:00:00 gmt", want},
+		{"12 Jul 2024 13:00:00 +02:00", want},
+		{"Fri, 12 Jul 24 11:00:00 UT", want},
+		{"2024-07-12T13:00:00+02:00", want},
+		{"2024-07-12T11:00:00.000Z", want},
+		{"2024-07-12T13:00:00+0200", want},
+		{"2024-07-12 11:00:00", want},
+		{"2024-07-12T11:00", want},
+		{"Sat, 6 Jan 2024 10:00:00 EST", time.Date(2024, time.January, 6, 15, 0, 0, 0, time.UTC)},
+		{"Fri, 12 Jul 2024 11:00:00 XYZT", want},
+		{"Fri, 12 Jul 2024 11:00:00 BRT", want},
+		{"July 12, 2024", time.Date(2024, time.July, 12, 0, 0, 0, 0, time.UTC)},
+		{"2024-07-12", time.Date(2024, time.July, 12, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.value)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.value, got.UTC(), tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "Fri, 12 Jul 2024 noon"} {
+		if _, err := ParseTime(value); err == nil {
+			t.Errorf("ParseTime(%q) should fail", value)
+		}
+	}
+}
+
+func TestPublishedAt(t *testing.T) {
+	now := time.Date(2024, time.July, 20, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry rss.Entry
+		want  time.Time
+	}{
+		{"published", rss.Entry{Published: str, Updated: "2024-07-15T00:00:00Z"}, time.Date(2024, time.July, 12, 11, 0, 0, 0, time.UTC)},
+		{"updated", rss.Entry{Published: "soon", Updated: "2024-07-15T00:00:00Z"}, time.Date(2024, time.July, 15, 0, 0, 0, 0, time.UTC)},
+		{"dc:date", rss.Entry{Date: "2024-07-16"}, time.Date(2024, time.July, 16, 0, 0, 0, 0, time.UTC)},
+		{"first seen", rss.Entry{}, now},
+		{"future", rss.Entry{Published: "2030-01-01T00:00:00Z"}, now},
+	}
+	for _, tt := range tests {
+		if got := PublishedAt(tt.entry, now); !got.Equal(tt.want) {
+			t.Errorf("%s: PublishedAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
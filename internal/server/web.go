@@ -1,14 +1,18 @@
 package server
 
 import (
+	"database/sql"
+	"errors"
 	"html/template"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/odin-software/nyusu/internal/database"
 	"github.com/odin-software/nyusu/internal/rss"
+	"github.com/odin-software/nyusu/internal/sanitize"
 )
 
 func checkError(err error) {
@@ -28,6 +32,7 @@ func getTemplateFuncMap() template.FuncMap {
 		"sub": func(a, b int32) int32 {
 			return a - b
 		},
+		"hasPrefix": strings.HasPrefix,
 	}
 }
 
@@ -40,11 +45,22 @@ func TestRssParsing(url string) {
 type BaseData struct {
 	Authenticated bool
 	Branding      Branding
+	CSRFToken     string
+}
+
+// baseData fills in the layout fields every page shares.
+func (cfg *APIConfig) baseData(auth AuthResult) BaseData {
+	data := BaseData{Authenticated: auth.IsAuthenticated, Branding: cfg.Branding}
+	if auth.SessionData != nil {
+		data.CSRFToken = auth.SessionData.CsrfToken
+	}
+	return data
 }
 
 type IndexData struct {
 	BaseData
 	Posts      []database.GetPostsByUserWithBookmarksRow
+	UnreadOnly bool
 	Pagination Pagination
 }
 
@@ -55,22 +71,45 @@ type AuthPageData struct {
 
 type AddFeedData struct {
 	BaseData
-	Error string
+	Error   string
+	Imports []database.OpmlImport
+}
+
+type DiscoverFeedsData struct {
+	BaseData
+	Url        string
+	Candidates []rss.Candidate
 }
 
 type AllFeedsData struct {
 	BaseData
-	Error      string
-	Feeds      []database.GetAllFeedFollowsByEmailRow
-	Pagination Pagination
+	Error   string
+	Feeds   []database.GetAllFeedFollowsByEmailRow
+	Folders []database.GetFoldersByUserRow
+	// FeedFolders lists the folders of each subscription by feed follow ID.
+	FeedFolders map[int64][]database.GetFeedFollowFoldersRow
+	Pagination  Pagination
 }
 
 type FeedPostsData struct {
 	BaseData
+	FeedID     int64
 	Posts      []database.GetPostsByUserAndFeedWithBookmarksRow
 	Pagination Pagination
 }
 
+// PostData is the reader page of a single post. Newer and Older are its
+// neighbours in the timeline it was opened from, nil at either end, and
+// Timeline is the query string that keeps the navigation in that timeline.
+type PostData struct {
+	BaseData
+	Post     database.GetPostForUserRow
+	Content  template.HTML
+	Newer    *database.GetNewerPostRow
+	Older    *database.GetOlderPostRow
+	Timeline template.URL
+}
+
 type BookmarksData struct {
 	BaseData
 	Posts      []database.GetBookmarkedPostsByDateRow
@@ -79,24 +118,26 @@ type BookmarksData struct {
 
 func (cfg *APIConfig) getHome(w http.ResponseWriter, r *http.Request, auth AuthResult) {
 	fm := getTemplateFuncMap()
-	t, err := template.New("layout").Funcs(fm).ParseFiles("html/layout.html", "html/index.html")
+	t, err := template.New("layout").Funcs(fm).ParseFiles("html/layout.html", "html/index.html", "html/media.html")
 	if err != nil {
 		panic(err)
 	}
 
 	if !auth.IsAuthenticated {
 		t.Execute(w, IndexData{
-			BaseData: BaseData{Authenticated: false, Branding: cfg.Branding},
+			BaseData: cfg.baseData(auth),
 		})
 		return
 	}
 
 	pageNumber := GetPageNumber(r)
 	limit, offset := GetPageSizeNumber(r)
+	unreadOnly := GetUnreadOnly(r)
 	posts, err := cfg.DB.GetPostsByUserWithBookmarks(cfg.ctx, database.GetPostsByUserWithBookmarksParams{
-		Email:  auth.SessionData.Email,
-		Limit:  limit + 1,
-		Offset: offset,
+		Email:      auth.SessionData.Email,
+		UnreadOnly: unreadOnly,
+		PageLimit:  limit + 1,
+		PageOffset: offset,
 	})
 	if err != nil {
 		log.Print(err)
@@ -110,8 +151,9 @@ func (cfg *APIConfig) getHome(w http.ResponseWriter, r *http.Request, auth AuthR
 	}
 
 	err = t.Execute(w, IndexData{
-		BaseData:   BaseData{Authenticated: true, Branding: cfg.Branding},
+		BaseData:   cfg.baseData(auth),
 		Posts:      posts,
+		UnreadOnly: unreadOnly,
 		Pagination: pag,
 	})
 	if err != nil {
@@ -126,13 +168,21 @@ func (cfg *APIConfig) GetHome(w http.ResponseWriter, r *http.Request) {
 func (cfg *APIConfig) getAddFeed(w http.ResponseWriter, r *http.Request, auth AuthResult) {
 	query := r.URL.Query()
 	error := query.Get("error")
-	t, err := template.ParseFiles("html/layout.html", "html/add.html")
+	imports, err := cfg.DB.GetOpmlImportsByUser(cfg.ctx, database.GetOpmlImportsByUserParams{
+		UserID: auth.SessionData.UserID2,
+		Limit:  5,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/add.html")
 	if err != nil {
 		panic(err)
 	}
 	err = t.ExecuteTemplate(w, "layout", AddFeedData{
-		BaseData: BaseData{Authenticated: true, Branding: cfg.Branding},
+		BaseData: cfg.baseData(auth),
 		Error:    error,
+		Imports:  imports,
 	})
 	if err != nil {
 		panic(err)
@@ -143,6 +193,23 @@ func (cfg *APIConfig) GetAddFeed(w http.ResponseWriter, r *http.Request) {
 	cfg.RequireAuth(cfg.getAddFeed)(w, r)
 }
 
+// renderDiscoveredFeeds lets the user pick which of the feeds found on a
+// website to subscribe to.
+func (cfg *APIConfig) renderDiscoveredFeeds(w http.ResponseWriter, auth AuthResult, url string, candidates []rss.Candidate) {
+	t, err := template.ParseFiles("html/layout.html", "html/discover.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", DiscoverFeedsData{
+		BaseData:   cfg.baseData(auth),
+		Url:        url,
+		Candidates: candidates,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
 func (cfg *APIConfig) getAllFeeds(w http.ResponseWriter, r *http.Request, auth AuthResult) {
 	query := r.URL.Query()
 	error := query.Get("error")
@@ -165,15 +232,34 @@ func (cfg *APIConfig) getAllFeeds(w http.ResponseWriter, r *http.Request, auth A
 		feeds = feeds[:limit]
 	}
 
+	folders, err := cfg.DB.GetFoldersByUser(cfg.ctx, auth.SessionData.UserID2)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	assigned, err := cfg.DB.GetFeedFollowFolders(cfg.ctx, auth.SessionData.UserID2)
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+	feedFolders := map[int64][]database.GetFeedFollowFoldersRow{}
+	for _, a := range assigned {
+		feedFolders[a.FeedFollowID] = append(feedFolders[a.FeedFollowID], a)
+	}
+
 	t, err := template.New("layout").Funcs(getTemplateFuncMap()).ParseFiles("html/layout.html", "html/feeds.html")
 	if err != nil {
 		panic(err)
 	}
 	err = t.ExecuteTemplate(w, "layout", AllFeedsData{
-		BaseData:   BaseData{Authenticated: true, Branding: cfg.Branding},
-		Error:      error,
-		Feeds:      feeds,
-		Pagination: pag,
+		BaseData:    cfg.baseData(auth),
+		Error:       error,
+		Feeds:       feeds,
+		Folders:     folders,
+		FeedFolders: feedFolders,
+		Pagination:  pag,
 	})
 	if err != nil {
 		panic(err)
@@ -212,12 +298,13 @@ func (cfg *APIConfig) getFeedPosts(w http.ResponseWriter, r *http.Request, auth
 		posts = posts[:limit]
 	}
 
-	t, err := template.New("layout.html").Funcs(fm).ParseFiles("html/layout.html", "html/feeds_posts.html")
+	t, err := template.New("layout.html").Funcs(fm).ParseFiles("html/layout.html", "html/feeds_posts.html", "html/media.html")
 	if err != nil {
 		panic(err)
 	}
 	err = t.ExecuteTemplate(w, "layout", FeedPostsData{
-		BaseData:   BaseData{Authenticated: true, Branding: cfg.Branding},
+		BaseData:   cfg.baseData(auth),
+		FeedID:     int64(feedId),
 		Posts:      posts,
 		Pagination: pag,
 	})
@@ -230,6 +317,108 @@ func (cfg *APIConfig) GetFeedPosts(w http.ResponseWriter, r *http.Request) {
 	cfg.RequireAuth(cfg.getFeedPosts)(w, r)
 }
 
+func (cfg *APIConfig) getPost(w http.ResponseWriter, r *http.Request, auth AuthResult) {
+	fm := getTemplateFuncMap()
+	postId, err := strconv.ParseInt(r.PathValue("postId"), 10, 64)
+	if err != nil {
+		notFoundHandler(w)
+		return
+	}
+	userId := auth.SessionData.UserID2
+
+	// Posts from feeds the user doesn't follow are reported as missing.
+	post, err := cfg.DB.GetPostForUser(cfg.ctx, database.GetPostForUserParams{
+		UserID: userId,
+		ID:     postId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		notFoundHandler(w)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		internalServerErrorHandler(w)
+		return
+	}
+
+	// Opening a post marks it read.
+	if post.IsRead == 0 {
+		_, err = cfg.DB.MarkPostRead(cfg.ctx, database.MarkPostReadParams{
+			UserID: userId,
+			PostID: post.ID,
+		})
+		if err != nil {
+			log.Println(err)
+		} else {
+			post.IsRead = 1
+		}
+	}
+
+	// ?feed= and ?folder= keep previous/next inside that feed's or folder's
+	// timeline instead of the home timeline.
+	var feedId, folderId sql.NullInt64
+	var timeline template.URL
+	query := r.URL.Query()
+	if id, err := strconv.ParseInt(query.Get("feed"), 10, 64); err == nil && id == post.FeedID {
+		feedId = sql.NullInt64{Int64: id, Valid: true}
+		timeline = template.URL("?feed=" + strconv.FormatInt(id, 10))
+	} else if id, err := strconv.ParseInt(query.Get("folder"), 10, 64); err == nil {
+		folderId = sql.NullInt64{Int64: id, Valid: true}
+		timeline = template.URL("?folder=" + strconv.FormatInt(id, 10))
+	}
+
+	data := PostData{
+		BaseData: cfg.baseData(auth),
+		Post:     post,
+		Timeline: timeline,
+	}
+	content := post.Content.String
+	if content == "" {
+		content = post.Description.String
+	}
+	// Content is sanitized on ingest too; this covers posts stored before
+	// that and any change to the allowlist since.
+	data.Content = template.HTML(sanitize.HTML(content, post.Url))
+
+	newer, err := cfg.DB.GetNewerPost(cfg.ctx, database.GetNewerPostParams{
+		UserID:      userId,
+		PublishedAt: post.PublishedAt,
+		ID:          post.ID,
+		FeedID:      feedId,
+		FolderID:    folderId,
+	})
+	if err == nil {
+		data.Newer = &newer
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Println(err)
+	}
+	older, err := cfg.DB.GetOlderPost(cfg.ctx, database.GetOlderPostParams{
+		UserID:      userId,
+		PublishedAt: post.PublishedAt,
+		ID:          post.ID,
+		FeedID:      feedId,
+		FolderID:    folderId,
+	})
+	if err == nil {
+		data.Older = &older
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Println(err)
+	}
+
+	t, err := template.New("layout.html").Funcs(fm).ParseFiles("html/layout.html", "html/post.html")
+	if err != nil {
+		panic(err)
+	}
+	err = t.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (cfg *APIConfig) GetPost(w http.ResponseWriter, r *http.Request) {
+	cfg.RequireAuth(cfg.getPost)(w, r)
+}
+
 func (cfg *APIConfig) getBookmarks(w http.ResponseWriter, r *http.Request, auth AuthResult) {
 	fm := getTemplateFuncMap()
 
@@ -251,12 +440,12 @@ func (cfg *APIConfig) getBookmarks(w http.ResponseWriter, r *http.Request, auth
 		posts = posts[:limit]
 	}
 
-	t, err := template.New("layout.html").Funcs(fm).ParseFiles("html/layout.html", "html/bookmarks.html")
+	t, err := template.New("layout.html").Funcs(fm).ParseFiles("html/layout.html", "html/bookmarks.html", "html/media.html")
 	if err != nil {
 		panic(err)
 	}
 	err = t.ExecuteTemplate(w, "layout", BookmarksData{
-		BaseData:   BaseData{Authenticated: true, Branding: cfg.Branding},
+		BaseData:   cfg.baseData(auth),
 		Posts:      posts,
 		Pagination: pag,
 	})
@@ -274,10 +463,7 @@ func (cfg *APIConfig) getAbout(w http.ResponseWriter, r *http.Request, auth Auth
 	if err != nil {
 		panic(err)
 	}
-	err = t.ExecuteTemplate(w, "layout", BaseData{
-		Authenticated: auth.IsAuthenticated,
-		Branding:      cfg.Branding,
-	})
+	err = t.ExecuteTemplate(w, "layout", cfg.baseData(auth))
 	if err != nil {
 		panic(err)
 	}
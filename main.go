@@ -24,25 +24,89 @@ func main() {
 	mux.HandleFunc("GET /add", cfg.GetAddFeed)
 	mux.HandleFunc("GET /feeds", cfg.GetAllFeeds)
 	mux.HandleFunc("GET /feeds/{feedId}", cfg.GetFeedPosts)
+	mux.HandleFunc("GET /feeds/export.opml", cfg.ExportOpml)
+	mux.HandleFunc("GET /imports/{importId}", cfg.GetOpmlImport)
+	mux.HandleFunc("GET /posts/{postId}", cfg.GetPost)
 	mux.HandleFunc("GET /bookmarks", cfg.GetBookmarks)
+	mux.HandleFunc("GET /search", cfg.GetSearch)
+	mux.HandleFunc("GET /rules", cfg.GetRules)
+	mux.HandleFunc("GET /settings", cfg.GetSettings)
+	mux.HandleFunc("GET /folders", cfg.GetFolders)
+	mux.HandleFunc("GET /folders/{folderId}", cfg.GetFolderPosts)
 	mux.HandleFunc("GET /about", cfg.GetAbout)
 
 	// Action endpoints.
 	mux.HandleFunc("POST /users/logout", cfg.LogoutUser)
 	mux.HandleFunc("POST /feed", cfg.CreateFeed)
 	mux.HandleFunc("POST /unsubscribe/{feedFollowId}", cfg.UnsubscribeFeed)
+	mux.HandleFunc("POST /feeds/{feedId}/retry", cfg.RetryFeed)
+	mux.HandleFunc("POST /feeds/import", cfg.ImportOpml)
+	mux.HandleFunc("POST /read", cfg.MarkAllRead)
+	mux.HandleFunc("POST /folders", cfg.CreateFolder)
+	mux.HandleFunc("POST /folders/{folderId}/rename", cfg.RenameFolder)
+	mux.HandleFunc("POST /folders/{folderId}/delete", cfg.RemoveFolder)
+	mux.HandleFunc("POST /feed_follows/{feedFollowId}/settings", cfg.UpdateFeedFollowSettings)
+	mux.HandleFunc("POST /feed_follows/{feedFollowId}/folders", cfg.AddToFolder)
+	mux.HandleFunc("POST /feed_follows/{feedFollowId}/folders/{folderId}/remove", cfg.RemoveFromFolder)
+	mux.HandleFunc("POST /rules", cfg.CreateRule)
+	mux.HandleFunc("POST /rules/{ruleId}/apply", cfg.ApplyRule)
+	mux.HandleFunc("POST /rules/{ruleId}/delete", cfg.RemoveRule)
+	mux.HandleFunc("POST /settings/tokens", cfg.CreateApiToken)
+	mux.HandleFunc("POST /settings/tokens/{tokenId}/revoke", cfg.RevokeApiToken)
 
-	mux.HandleFunc("GET /v1/feeds", cfg.CORS(cfg.GetAllFeeds2))                                      // get
-	mux.HandleFunc("GET /v1/feed_follows", cfg.CORS(cfg.MiddlewareAuth(cfg.GetFeedFollowsFromUser))) // get
-	mux.HandleFunc("DELETE /v1/feed_follows/{feedFollowId}", cfg.DeleteFeedFollows)                  // delete
+	mux.HandleFunc("GET /v1/users/me", cfg.CORS(cfg.MiddlewareAuth(cfg.GetAuthUser))) // get
 
-	mux.HandleFunc("DELETE /v1/posts/bookmarks/{postId}", cfg.CORS(cfg.MiddlewareAuth(cfg.UnbookmarkPost))) // delete
-	mux.HandleFunc("POST /v1/posts/bookmarks/{postId}", cfg.CORS(cfg.MiddlewareAuth(cfg.BookmarkPost)))     // post
-	mux.HandleFunc("GET /v1/posts/bookmarks", cfg.CORS(cfg.MiddlewareAuth(cfg.GetBookmarkedPosts)))         // get
+	mux.HandleFunc("GET /v1/feeds", cfg.CORS(cfg.GetAllFeeds2))                                                   // get
+	mux.HandleFunc("GET /v1/feeds/{feedId}", cfg.CORS(cfg.MiddlewareAuth(cfg.GetFeed)))                           // get
+	mux.HandleFunc("GET /v1/feeds/{feedId}/posts", cfg.CORS(cfg.MiddlewareAuth(cfg.GetFeedPostsFromUser)))        // get
+	mux.HandleFunc("GET /v1/feed_follows", cfg.CORS(cfg.MiddlewareAuth(cfg.GetFeedFollowsFromUser)))              // get
+	mux.HandleFunc("POST /v1/feed_follows", cfg.CORS(cfg.MiddlewareAuth(cfg.CreateFeedFollows)))                  // post
+	mux.HandleFunc("DELETE /v1/feed_follows", cfg.CORS(cfg.MiddlewareAuth(cfg.DeleteFeedFollowByUrl)))            // delete
+	mux.HandleFunc("PATCH /v1/feed_follows/{feedFollowId}", cfg.CORS(cfg.MiddlewareAuth(cfg.UpdateFeedFollow)))   // patch
+	mux.HandleFunc("DELETE /v1/feed_follows/{feedFollowId}", cfg.CORS(cfg.MiddlewareAuth(cfg.DeleteFeedFollows))) // delete
+	mux.HandleFunc("POST /v1/feeds/{feedId}/retry", cfg.CORS(cfg.MiddlewareAuth(cfg.RetryFeedFetch)))             // post
+
+	mux.HandleFunc("GET /v1/folders", cfg.CORS(cfg.MiddlewareAuth(cfg.GetUserFolders)))                                                   // get
+	mux.HandleFunc("POST /v1/folders", cfg.CORS(cfg.MiddlewareAuth(cfg.CreateUserFolder)))                                                // post
+	mux.HandleFunc("PATCH /v1/folders/{folderId}", cfg.CORS(cfg.MiddlewareAuth(cfg.UpdateFolder)))                                        // patch
+	mux.HandleFunc("DELETE /v1/folders/{folderId}", cfg.CORS(cfg.MiddlewareAuth(cfg.DeleteFolder)))                                       // delete
+	mux.HandleFunc("GET /v1/folders/{folderId}/posts", cfg.CORS(cfg.MiddlewareAuth(cfg.GetFolderPostsFromUser)))                          // get
+	mux.HandleFunc("POST /v1/folders/{folderId}/feed_follows", cfg.CORS(cfg.MiddlewareAuth(cfg.AddFolderFeedFollow)))                     // post
+	mux.HandleFunc("DELETE /v1/folders/{folderId}/feed_follows/{feedFollowId}", cfg.CORS(cfg.MiddlewareAuth(cfg.RemoveFolderFeedFollow))) // delete
+
+	mux.HandleFunc("GET /v1/rules", cfg.CORS(cfg.MiddlewareAuth(cfg.GetUserRules)))                  // get
+	mux.HandleFunc("POST /v1/rules", cfg.CORS(cfg.MiddlewareAuth(cfg.CreateUserRule)))               // post
+	mux.HandleFunc("DELETE /v1/rules/{ruleId}", cfg.CORS(cfg.MiddlewareAuth(cfg.DeleteUserRule)))    // delete
+	mux.HandleFunc("POST /v1/rules/{ruleId}/apply", cfg.CORS(cfg.MiddlewareAuth(cfg.ApplyUserRule))) // post
+
+	mux.HandleFunc("DELETE /v1/posts/bookmarks/{postId}", cfg.CORS(cfg.MiddlewareAuth(cfg.UnbookmarkPost)))  // delete
+	mux.HandleFunc("POST /v1/posts/bookmarks/{postId}", cfg.CORS(cfg.MiddlewareAuth(cfg.BookmarkPost)))      // post
+	mux.HandleFunc("GET /v1/posts/bookmarks", cfg.CORS(cfg.MiddlewareAuth(cfg.GetBookmarkedPosts)))          // get
+	mux.HandleFunc("GET /v1/search", cfg.CORS(cfg.MiddlewareAuth(cfg.SearchPosts)))                          // get
+	mux.HandleFunc("GET /v1/posts", cfg.CORS(cfg.MiddlewareAuth(cfg.GetPosts)))                              // get
+	mux.HandleFunc("GET /v1/posts/{postId}", cfg.CORS(cfg.MiddlewareAuth(cfg.GetPostFromUser)))              // get
+	mux.HandleFunc("POST /v1/posts/reads", cfg.CORS(cfg.MiddlewareAuth(cfg.MarkPostsRead)))                  // post
+	mux.HandleFunc("POST /v1/posts/reads/{postId}", cfg.CORS(cfg.MiddlewareAuth(cfg.MarkPostRead)))          // post
+	mux.HandleFunc("DELETE /v1/posts/reads/{postId}", cfg.CORS(cfg.MiddlewareAuth(cfg.MarkPostUnread)))      // delete
+	mux.HandleFunc("GET /v1/posts/{postId}/enclosures", cfg.CORS(cfg.MiddlewareAuth(cfg.GetPostEnclosures))) // get
+
+	// Google Reader API, for mobile clients.
+	mux.HandleFunc("POST /api/greader/accounts/ClientLogin", cfg.GReaderClientLogin)
+	mux.HandleFunc("GET /api/greader/reader/api/0/token", cfg.MiddlewareGReader(cfg.GReaderToken))
+	mux.HandleFunc("GET /api/greader/reader/api/0/user-info", cfg.MiddlewareGReader(cfg.GReaderUserInfo))
+	mux.HandleFunc("GET /api/greader/reader/api/0/subscription/list", cfg.MiddlewareGReader(cfg.GReaderSubscriptions))
+	mux.HandleFunc("GET /api/greader/reader/api/0/tag/list", cfg.MiddlewareGReader(cfg.GReaderTags))
+	mux.HandleFunc("GET /api/greader/reader/api/0/unread-count", cfg.MiddlewareGReader(cfg.GReaderUnreadCount))
+	mux.HandleFunc("GET /api/greader/reader/api/0/stream/items/ids", cfg.MiddlewareGReader(cfg.GReaderStreamItemIDs))
+	mux.HandleFunc("GET /api/greader/reader/api/0/stream/items/contents", cfg.MiddlewareGReader(cfg.GReaderItemContents))
+	mux.HandleFunc("POST /api/greader/reader/api/0/stream/items/contents", cfg.MiddlewareGReader(cfg.GReaderItemContents))
+	mux.HandleFunc("GET /api/greader/reader/api/0/stream/contents/{streamId...}", cfg.MiddlewareGReader(cfg.GReaderStreamContents))
+	mux.HandleFunc("POST /api/greader/reader/api/0/edit-tag", cfg.MiddlewareGReaderWrite(cfg.GReaderEditTag))
+	mux.HandleFunc("POST /api/greader/reader/api/0/mark-all-as-read", cfg.MiddlewareGReaderWrite(cfg.GReaderMarkAllAsRead))
 
 	go func() {
 		for range ticker.C {
-			cfg.FetchPastFeeds(5)
+			cfg.FetchPastFeeds()
 		}
 	}()
 
@@ -0,0 +1,26 @@
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (user_id, name, token_hash, scope)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetApiTokensByUser :many
+SELECT id, name, scope, created_at, last_used_at
+FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetApiTokenByHash :one
+SELECT t.id, t.scope,
+       u.id AS user_id, u.name, u.email, u.sub, u.created_at AS user_created_at, u.updated_at AS user_updated_at
+FROM api_tokens t
+INNER JOIN users u ON t.user_id = u.id
+WHERE t.token_hash = $1;
+
+-- name: TouchApiToken :exec
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteApiToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,12 @@
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (post_id, url, mime_type, length, duration, thumbnail_url)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetPostEnclosuresByUser :many
+SELECT pe.*
+FROM post_enclosures pe
+INNER JOIN posts p ON p.id = pe.post_id
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE pe.post_id = $1 AND ff.user_id = $2
+ORDER BY pe.id;
@@ -5,19 +5,49 @@ FROM feeds
 LIMIT $1
 OFFSET $2;
 
--- name: GetNextFeedsToFetch :many
-SELECT id, name, url
+-- name: GetDueFeeds :many
+SELECT id, name, url, etag, last_modified, consecutive_failures, update_hint_seconds
 FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT $1;
+WHERE NOT disabled AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+ORDER BY next_fetch_at ASC NULLS FIRST;
 
 -- name: MarkFeedFetched :exec
 UPDATE feeds
 SET
 	last_fetched_at = NOW(),
+	etag = $2,
+	last_modified = $3,
+	next_fetch_at = $4,
+	update_hint_seconds = $5,
+	consecutive_failures = 0,
 	updated_at = NOW()
 WHERE id = $1;
 
+-- name: MarkFeedFailed :exec
+UPDATE feeds
+SET
+	consecutive_failures = consecutive_failures + 1,
+	next_fetch_at = sqlc.arg(next_fetch_at),
+	last_error = sqlc.arg(last_error),
+	last_error_at = NOW(),
+	disabled = consecutive_failures + 1 >= sqlc.arg(max_failures)::int,
+	updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: EnableFeed :exec
+UPDATE feeds
+SET
+	disabled = FALSE,
+	consecutive_failures = 0,
+	next_fetch_at = NULL,
+	updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetFeedById :one
+SELECT *
+FROM feeds
+WHERE id = $1;
+
 -- name: GetFeedByUrl :one
 SELECT *
 FROM feeds
@@ -35,24 +65,71 @@ FROM feed_follows
 WHERE feed_id = $1 AND user_id = $2;
 
 -- name: GetFeedFollowsFromUser :many
-SELECT id, user_id, feed_id
-FROM feed_follows
-WHERE user_id = $1;
+SELECT ff.id, ff.user_id, ff.feed_id, COALESCE(ff.custom_name, f.name) AS name, f.url, f.last_fetched_at,
+       f.last_error, f.last_error_at, f.consecutive_failures, f.disabled,
+       ff.custom_name, ff.hide_from_home, ff.sort_order,
+       pc.unread_count, pc.total_count, pc.newest_post_at
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+CROSS JOIN LATERAL (
+  SELECT COUNT(*) AS total_count,
+         COUNT(*) FILTER (WHERE ur.post_id IS NULL) AS unread_count,
+         MAX(p.published_at) AS newest_post_at
+  FROM posts p
+  LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+  WHERE p.feed_id = f.id
+    AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+) pc
+WHERE ff.user_id = $1;
+
+-- name: GetSubscriptionsByUser :many
+SELECT ff.id, ff.feed_id, COALESCE(ff.custom_name, f.name) AS name, f.url, f.link, ff.created_at
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+WHERE ff.user_id = $1
+ORDER BY name;
 
 -- name: CreateFeedFollows :one
 INSERT INTO feed_follows (user_id, feed_id)
 VALUES ($1, $2)
 RETURNING *;
 
--- name: DeleteFeedFollows :exec
+-- name: GetFeedFollowForUser :one
+SELECT * FROM feed_follows
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateFeedFollowSettings :one
+UPDATE feed_follows
+SET custom_name = $1, hide_from_home = $2, sort_order = $3, updated_at = NOW()
+WHERE id = $4 AND user_id = $5
+RETURNING *;
+
+-- name: DeleteFeedFollows :execrows
 DELETE FROM feed_follows
-WHERE id = $1;
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteFeedFollowByUrl :execrows
+DELETE FROM feed_follows ff
+USING feeds f
+WHERE ff.feed_id = f.id AND ff.user_id = $1 AND f.url = $2;
 
 -- name: GetAllFeedFollowsByEmail :many
-SELECT f.id, f."name", f.url, f.link, f.description, f.created_at, ff.id AS feed_follow_id
+SELECT f.id, COALESCE(ff.custom_name, f.name) AS name, f.url, f.link, f.description, f.created_at, ff.id AS feed_follow_id,
+       f.last_error, f.last_error_at, f.consecutive_failures, f.disabled,
+       ff.custom_name, ff.hide_from_home, ff.sort_order,
+       pc.unread_count, pc.total_count, pc.newest_post_at
 FROM feed_follows ff
 INNER JOIN feeds f ON ff.feed_id = f.id
 INNER JOIN users u ON ff.user_id = u.id
+CROSS JOIN LATERAL (
+  SELECT COUNT(*) AS total_count,
+         COUNT(*) FILTER (WHERE ur.post_id IS NULL) AS unread_count,
+         MAX(p.published_at) AS newest_post_at
+  FROM posts p
+  LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+  WHERE p.feed_id = f.id
+    AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+) pc
 WHERE u.email = $1
 LIMIT $2
 OFFSET $3;
@@ -0,0 +1,53 @@
+-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES ($1, $2)
+ON CONFLICT (post_id, name) DO NOTHING;
+
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (user_id, feed_id, field, match_type, pattern, action)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetFilterRulesByUser :many
+SELECT fr.id, fr.feed_id, f.name AS feed_name, fr.field, fr.match_type, fr.pattern, fr.action, fr.created_at,
+       (SELECT COUNT(*) FROM users_hidden_posts uh WHERE uh.rule_id = fr.id) AS hidden_count
+FROM filter_rules fr
+LEFT JOIN feeds f ON fr.feed_id = f.id
+WHERE fr.user_id = $1
+ORDER BY fr.created_at;
+
+-- name: GetFilterRuleForUser :one
+SELECT * FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetFilterRulesForFeed :many
+SELECT fr.* FROM filter_rules fr
+INNER JOIN feed_follows ff ON ff.user_id = fr.user_id AND ff.feed_id = sqlc.arg(feed_id)
+WHERE fr.feed_id IS NULL OR fr.feed_id = sqlc.arg(feed_id)
+ORDER BY fr.id;
+
+-- name: GetPostsForRule :many
+SELECT p.id, p.title, p.author, p.url,
+       (coalesce(p.description, '') || ' ' || coalesce(p.content, ''))::text AS body,
+       coalesce((SELECT string_agg(pc.name, E'\n') FROM post_categories pc WHERE pc.post_id = p.id), '')::text AS categories
+FROM feed_follows ff
+INNER JOIN posts p ON p.feed_id = ff.feed_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(feed_id)::bigint IS NULL OR ff.feed_id = sqlc.narg(feed_id))
+  AND p.id > sqlc.arg(after_id)
+ORDER BY p.id
+LIMIT sqlc.arg(page_limit);
+
+-- name: HidePost :exec
+INSERT INTO users_hidden_posts (user_id, post_id, rule_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: AutoBookmarkPost :exec
+INSERT INTO users_bookmarks (user_id, post_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, post_id) DO NOTHING;
@@ -0,0 +1,78 @@
+-- name: CreateFolder :one
+INSERT INTO folders (user_id, name)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: UpsertFolder :one
+INSERT INTO folders (user_id, name)
+VALUES ($1, $2)
+ON CONFLICT (user_id, name) DO UPDATE
+SET updated_at = folders.updated_at
+RETURNING *;
+
+-- name: GetFoldersByUser :many
+SELECT fo.id, fo.name, fo.created_at, COUNT(fff.id) AS feed_count
+FROM folders fo
+LEFT JOIN feed_follow_folders fff ON fff.folder_id = fo.id
+WHERE fo.user_id = $1
+GROUP BY fo.id
+ORDER BY fo.name;
+
+-- name: GetFolderForUser :one
+SELECT * FROM folders
+WHERE id = $1 AND user_id = $2;
+
+-- name: RenameFolder :execrows
+UPDATE folders
+SET name = $1, updated_at = NOW()
+WHERE id = $2 AND user_id = $3;
+
+-- name: DeleteFolder :execrows
+DELETE FROM folders
+WHERE id = $1 AND user_id = $2;
+
+-- name: AddFeedFollowToFolder :execrows
+INSERT INTO feed_follow_folders (feed_follow_id, folder_id)
+SELECT ff.id, fo.id
+FROM feed_follows ff
+INNER JOIN folders fo ON fo.user_id = ff.user_id
+WHERE ff.id = sqlc.arg(feed_follow_id)
+  AND fo.id = sqlc.arg(folder_id)
+  AND ff.user_id = sqlc.arg(user_id)
+ON CONFLICT (feed_follow_id, folder_id) DO UPDATE
+SET created_at = feed_follow_folders.created_at;
+
+-- name: RemoveFeedFollowFromFolder :execrows
+DELETE FROM feed_follow_folders fff
+USING folders fo
+WHERE fff.folder_id = fo.id
+  AND fff.feed_follow_id = sqlc.arg(feed_follow_id)
+  AND fff.folder_id = sqlc.arg(folder_id)
+  AND fo.user_id = sqlc.arg(user_id);
+
+-- name: GetFeedFollowFolders :many
+SELECT fff.feed_follow_id, fo.id AS folder_id, fo.name
+FROM feed_follow_folders fff
+INNER JOIN folders fo ON fff.folder_id = fo.id
+WHERE fo.user_id = $1
+ORDER BY fo.name;
+
+-- name: GetPostsByUserAndFolder :many
+SELECT p.id, f.id as feed_id, COALESCE(ff.custom_name, f.name) AS name, p.title, p.author, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END as is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
+FROM feed_follow_folders fff
+INNER JOIN feed_follows ff ON fff.feed_follow_id = ff.id
+INNER JOIN feeds f ON ff.feed_id = f.id
+INNER JOIN posts p ON p.feed_id = f.id
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND fff.folder_id = sqlc.arg(folder_id)
+  AND (NOT sqlc.arg(unread_only)::boolean OR ur.post_id IS NULL)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY p.published_at DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
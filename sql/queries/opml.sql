@@ -0,0 +1,52 @@
+-- name: CreateOpmlImport :one
+INSERT INTO opml_imports (user_id, total)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: FinishOpmlImport :exec
+UPDATE opml_imports
+SET status = 'done', finished_at = NOW()
+WHERE id = $1;
+
+-- name: FailInterruptedOpmlImportItems :exec
+UPDATE opml_import_items
+SET status = 'failed', message = 'the import was interrupted by a restart', updated_at = NOW()
+WHERE status = 'pending'
+  AND import_id IN (SELECT id FROM opml_imports WHERE status = 'running');
+
+-- name: FinishInterruptedOpmlImports :exec
+UPDATE opml_imports
+SET status = 'done', finished_at = NOW()
+WHERE status = 'running';
+
+-- name: GetOpmlImportForUser :one
+SELECT * FROM opml_imports
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetOpmlImportsByUser :many
+SELECT * FROM opml_imports
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: CreateOpmlImportItem :one
+INSERT INTO opml_import_items (import_id, url, title)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateOpmlImportItem :exec
+UPDATE opml_import_items
+SET status = $1, message = $2, feed_id = $3, updated_at = NOW()
+WHERE id = $4;
+
+-- name: GetOpmlImportItems :many
+SELECT * FROM opml_import_items
+WHERE import_id = $1
+ORDER BY id;
+
+-- name: GetFeedFollowsForExport :many
+SELECT ff.id AS feed_follow_id, COALESCE(ff.custom_name, f.name) AS name, f.url, f.link
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+WHERE ff.user_id = $1
+ORDER BY name;
@@ -1,7 +1,14 @@
 -- name: CreatePost :one
-INSERT INTO posts (title, url, description, author, feed_id, published_at)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING *;
+INSERT INTO posts (title, url, description, content, author, feed_id, published_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, title, url, description, content, author, feed_id, published_at, created_at, updated_at;
+
+-- name: GetRecentPostDates :many
+SELECT published_at
+FROM posts
+WHERE feed_id = $1
+ORDER BY published_at DESC
+LIMIT $2;
 
 -- name: GetPostsByUser :many
 SELECT p.id, f.name, p.title, p.author, p.url, p.published_at
@@ -26,54 +33,210 @@ LIMIT $3
 OFFSET $4;
 
 -- name: GetBookmarkedPostsByPublished :many
-SELECT p.id, p.title, p.url, p.published_at
+SELECT p.id, p.title, p.url, p.published_at,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM users_bookmarks ub
 INNER JOIN posts p ON p.id = ub.post_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
 WHERE ub.user_id = $1
 ORDER BY p.published_at DESC
 LIMIT $2
 OFFSET $3;
 
 -- name: GetBookmarkedPostsByDate :many
-SELECT p.id, p.title, p.url, p.published_at, f.name
+SELECT p.id, p.title, p.url, p.published_at, COALESCE(ff.custom_name, f.name) AS name,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM users_bookmarks ub
 INNER JOIN posts p ON p.id = ub.post_id
 INNER JOIN feeds f ON p.feed_id = f.id
+LEFT JOIN feed_follows ff ON ff.feed_id = f.id AND ff.user_id = ub.user_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
 WHERE ub.user_id = $1
 ORDER BY ub.created_at DESC
 LIMIT $2
 OFFSET $3;
 
--- name: BookmarkPost :exec
+-- name: BookmarkPost :execrows
 INSERT INTO users_bookmarks (user_id, post_id)
-VALUES ($1, $2);
+SELECT ff.user_id, p.id
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = sqlc.arg(user_id) AND p.id = sqlc.arg(post_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET created_at = users_bookmarks.created_at;
 
 -- name: UnbookmarkPost :exec
 DELETE FROM users_bookmarks
 WHERE user_id = $1 AND post_id = $2;
 
+-- name: MarkPostRead :execrows
+INSERT INTO users_reads (user_id, post_id)
+SELECT ff.user_id, p.id
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = sqlc.arg(user_id) AND p.id = sqlc.arg(post_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET created_at = users_reads.created_at;
+
+-- name: MarkPostUnread :exec
+DELETE FROM users_reads
+WHERE user_id = $1 AND post_id = $2;
+
 -- name: GetPostsByUserWithBookmarks :many
-SELECT p.id, f.id as feed_id, f.name, p.title, p.author, p.url, p.published_at,
-       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked
+SELECT p.id, f.id as feed_id, COALESCE(ff.custom_name, f.name) AS name, p.title, p.author, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END as is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM feed_follows ff
 INNER JOIN users u ON ff.user_id = u.id
 INNER JOIN feeds f ON ff.feed_id = f.id
 INNER JOIN posts p ON p.feed_id = f.id
 LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = u.id
-WHERE u.email = $1
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = u.id
+LEFT JOIN post_media pe ON pe.post_id = p.id
+WHERE u.email = sqlc.arg(email)
+  AND (NOT sqlc.arg(unread_only)::boolean OR ur.post_id IS NULL)
+  AND NOT ff.hide_from_home
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = u.id AND uh.post_id = p.id)
 ORDER BY p.published_at DESC
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
 
 -- name: GetPostsByUserAndFeedWithBookmarks :many
-SELECT p.id, p.title, f.name, p.url, p.published_at,
-       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked
+SELECT p.id, p.title, COALESCE(ff.custom_name, f.name) AS name, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END as is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
 FROM feed_follows ff
 INNER JOIN feeds f ON ff.feed_id = f.id
 INNER JOIN posts p ON p.feed_id = f.id
 INNER JOIN users u ON ff.user_id = u.id
 LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = u.id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = u.id
+LEFT JOIN post_media pe ON pe.post_id = p.id
 WHERE u.email = $1 AND f.id = $2
-ORDER BY p.published_at DESC
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = u.id AND uh.post_id = p.id)
+ORDER BY CASE WHEN ff.sort_order = 'oldest' THEN p.published_at END ASC, p.published_at DESC
 LIMIT $3
 OFFSET $4;
+
+-- name: GetTimelinePosts :many
+SELECT p.id, f.id as feed_id, COALESCE(ff.custom_name, f.name) AS name, p.title, p.author, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END as is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END as is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+INNER JOIN posts p ON p.feed_id = f.id
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(feed_id)::bigint IS NULL OR f.id = sqlc.narg(feed_id)::bigint)
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = sqlc.narg(folder_id)::bigint
+  ))
+  AND (sqlc.narg(feed_id)::bigint IS NOT NULL OR sqlc.narg(folder_id)::bigint IS NOT NULL OR NOT ff.hide_from_home)
+  AND (NOT sqlc.arg(unread_only)::boolean OR ur.post_id IS NULL)
+  AND (NOT sqlc.arg(bookmarked_only)::boolean OR ub.post_id IS NOT NULL)
+  AND (sqlc.narg(published_after)::timestamptz IS NULL OR p.published_at >= sqlc.narg(published_after)::timestamptz)
+  AND (sqlc.narg(published_before)::timestamptz IS NULL OR p.published_at < sqlc.narg(published_before)::timestamptz)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY CASE WHEN sqlc.arg(oldest_first)::boolean THEN p.published_at END ASC, p.published_at DESC, p.id DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: GetPostForUser :one
+SELECT p.id, p.title, p.url, p.description, p.content, p.author, p.published_at,
+       f.id AS feed_id, COALESCE(ff.custom_name, f.name) AS feed_name,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_read
+FROM posts p
+INNER JOIN feeds f ON p.feed_id = f.id
+INNER JOIN feed_follows ff ON ff.feed_id = f.id AND ff.user_id = sqlc.arg(user_id)
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+WHERE p.id = sqlc.arg(id);
+
+-- name: GetNewerPost :one
+SELECT p.id, p.title
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id AND ff.user_id = sqlc.arg(user_id)
+WHERE (p.published_at > sqlc.arg(published_at)
+       OR (p.published_at = sqlc.arg(published_at) AND p.id > sqlc.arg(id)))
+  AND (sqlc.narg(feed_id)::bigint IS NULL OR p.feed_id = sqlc.narg(feed_id)::bigint)
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = sqlc.narg(folder_id)::bigint
+  ))
+  AND (sqlc.narg(feed_id)::bigint IS NOT NULL OR sqlc.narg(folder_id)::bigint IS NOT NULL OR NOT ff.hide_from_home)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY p.published_at ASC, p.id ASC
+LIMIT 1;
+
+-- name: GetOlderPost :one
+SELECT p.id, p.title
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id AND ff.user_id = sqlc.arg(user_id)
+WHERE (p.published_at < sqlc.arg(published_at)
+       OR (p.published_at = sqlc.arg(published_at) AND p.id < sqlc.arg(id)))
+  AND (sqlc.narg(feed_id)::bigint IS NULL OR p.feed_id = sqlc.narg(feed_id)::bigint)
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = sqlc.narg(folder_id)::bigint
+  ))
+  AND (sqlc.narg(feed_id)::bigint IS NOT NULL OR sqlc.narg(folder_id)::bigint IS NOT NULL OR NOT ff.hide_from_home)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY p.published_at DESC, p.id DESC
+LIMIT 1;
+
+-- name: MarkPostsRead :execrows
+INSERT INTO users_reads (user_id, post_id)
+SELECT ff.user_id, p.id
+FROM posts p
+INNER JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(feed_id)::bigint IS NULL OR p.feed_id = sqlc.narg(feed_id)::bigint)
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = sqlc.narg(folder_id)::bigint
+  ))
+  AND (sqlc.narg(older_than)::timestamptz IS NULL OR p.published_at < sqlc.narg(older_than)::timestamptz)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: GetStreamItemIDs :many
+SELECT p.id, p.published_at
+FROM feed_follows ff
+INNER JOIN posts p ON p.feed_id = ff.feed_id
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(feed_id)::bigint IS NULL OR ff.feed_id = sqlc.narg(feed_id)::bigint)
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = sqlc.narg(folder_id)::bigint
+  ))
+  AND (sqlc.narg(is_read)::boolean IS NULL OR (ur.post_id IS NOT NULL) = sqlc.narg(is_read)::boolean)
+  AND (NOT sqlc.arg(starred_only)::boolean OR ub.post_id IS NOT NULL)
+  AND (sqlc.narg(crawled_after)::timestamptz IS NULL OR p.created_at >= sqlc.narg(crawled_after)::timestamptz)
+  AND (sqlc.narg(crawled_before)::timestamptz IS NULL OR p.created_at < sqlc.narg(crawled_before)::timestamptz)
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+ORDER BY CASE WHEN sqlc.arg(oldest_first)::boolean THEN p.published_at END ASC, p.published_at DESC, p.id DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
+
+-- name: GetStreamItems :many
+SELECT p.id, p.title, p.url, p.description, p.content, p.author, p.published_at, p.created_at,
+       f.id AS feed_id, ff.id AS feed_follow_id, COALESCE(ff.custom_name, f.name) AS feed_name, f.url AS feed_url, f.link AS feed_link,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_read,
+       pe.url AS enclosure_url, pe.mime_type AS enclosure_type
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+INNER JOIN posts p ON p.feed_id = f.id
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+LEFT JOIN post_media pe ON pe.post_id = p.id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND p.id = ANY(sqlc.arg(ids)::bigint[]);
@@ -0,0 +1,29 @@
+-- name: SearchPosts :many
+SELECT p.id, f.id AS feed_id, COALESCE(ff.custom_name, f.name) AS name, p.title, p.author, p.url, p.published_at,
+       CASE WHEN ub.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_bookmarked,
+       CASE WHEN ur.post_id IS NOT NULL THEN 1 ELSE 0 END AS is_read,
+       ts_headline('simple',
+         regexp_replace(coalesce(p.content, p.description, p.title), '<[^>]*>', ' ', 'g'),
+         q.tsq,
+         'StartSel=[[[, StopSel=]]], MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "'
+       ) AS snippet
+FROM feed_follows ff
+INNER JOIN feeds f ON ff.feed_id = f.id
+INNER JOIN posts p ON p.feed_id = f.id
+CROSS JOIN websearch_to_tsquery('simple', sqlc.arg(query)) AS q(tsq)
+LEFT JOIN users_bookmarks ub ON ub.post_id = p.id AND ub.user_id = ff.user_id
+LEFT JOIN users_reads ur ON ur.post_id = p.id AND ur.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND p.search_vector @@ q.tsq
+  AND NOT EXISTS (SELECT 1 FROM users_hidden_posts uh WHERE uh.user_id = ff.user_id AND uh.post_id = p.id)
+  AND (sqlc.narg(feed_id)::bigint IS NULL OR f.id = sqlc.narg(feed_id))
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM feed_follow_folders fff
+    WHERE fff.feed_follow_id = ff.id AND fff.folder_id = sqlc.narg(folder_id)
+  ))
+  AND (sqlc.narg(published_after)::timestamptz IS NULL OR p.published_at >= sqlc.narg(published_after))
+  AND (sqlc.narg(published_before)::timestamptz IS NULL OR p.published_at < sqlc.narg(published_before))
+  AND (NOT sqlc.arg(bookmarked_only)::boolean OR ub.post_id IS NOT NULL)
+ORDER BY ts_rank(p.search_vector, q.tsq) DESC, p.published_at DESC
+LIMIT sqlc.arg(page_limit)
+OFFSET sqlc.arg(page_offset);
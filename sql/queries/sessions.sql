@@ -1,10 +1,10 @@
 -- name: CreateSession :one
-INSERT INTO sessions (token, user_id, expires_at)
-VALUES ($1, $2, $3)
+INSERT INTO sessions (token, user_id, expires_at, csrf_token)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetSessionByToken :one
-SELECT s.id, s.token, s.user_id, s.created_at, s.expires_at,
+SELECT s.id, s.token, s.user_id, s.created_at, s.expires_at, s.csrf_token,
        u.id AS user_id_2, u.name, u.email, u.sub, u.created_at AS user_created_at, u.updated_at AS user_updated_at
 FROM sessions s
 INNER JOIN users u ON s.user_id = u.id
@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE feeds ADD COLUMN etag TEXT;
+ALTER TABLE feeds ADD COLUMN last_modified TEXT;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN IF EXISTS last_modified;
+ALTER TABLE feeds DROP COLUMN IF EXISTS etag;
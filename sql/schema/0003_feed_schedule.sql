@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE feeds ADD COLUMN next_fetch_at TIMESTAMPTZ;
+ALTER TABLE feeds ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_feeds_next_fetch_at ON feeds(next_fetch_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_feeds_next_fetch_at;
+
+ALTER TABLE feeds DROP COLUMN IF EXISTS consecutive_failures;
+ALTER TABLE feeds DROP COLUMN IF EXISTS next_fetch_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+
+ALTER TABLE feeds ADD COLUMN last_error TEXT;
+ALTER TABLE feeds ADD COLUMN last_error_at TIMESTAMPTZ;
+ALTER TABLE feeds ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN IF EXISTS disabled;
+ALTER TABLE feeds DROP COLUMN IF EXISTS last_error_at;
+ALTER TABLE feeds DROP COLUMN IF EXISTS last_error;
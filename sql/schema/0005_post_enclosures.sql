@@ -0,0 +1,17 @@
+-- +goose Up
+
+CREATE TABLE post_enclosures (
+  id BIGSERIAL PRIMARY KEY,
+  post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  mime_type VARCHAR(255) NOT NULL DEFAULT '',
+  length BIGINT NOT NULL DEFAULT 0,
+  duration INTEGER NOT NULL DEFAULT 0,
+  thumbnail_url TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (post_id, url)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS post_enclosures;
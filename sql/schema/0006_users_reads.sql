@@ -0,0 +1,13 @@
+-- +goose Up
+
+CREATE TABLE users_reads (
+  id BIGSERIAL PRIMARY KEY,
+  post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (user_id, post_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS users_reads;
@@ -0,0 +1,25 @@
+-- +goose Up
+
+CREATE TABLE folders (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (user_id, name)
+);
+
+CREATE TABLE feed_follow_folders (
+  id BIGSERIAL PRIMARY KEY,
+  feed_follow_id BIGINT NOT NULL REFERENCES feed_follows(id) ON DELETE CASCADE,
+  folder_id BIGINT NOT NULL REFERENCES folders(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (feed_follow_id, folder_id)
+);
+
+CREATE INDEX idx_feed_follow_folders_folder_id ON feed_follow_folders(folder_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS feed_follow_folders;
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,37 @@
+-- +goose Up
+
+CREATE TABLE post_categories (
+  id BIGSERIAL PRIMARY KEY,
+  post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  UNIQUE (post_id, name)
+);
+
+CREATE TABLE filter_rules (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  feed_id BIGINT REFERENCES feeds(id) ON DELETE CASCADE,
+  field VARCHAR(20) NOT NULL,
+  match_type VARCHAR(20) NOT NULL,
+  pattern TEXT NOT NULL,
+  action VARCHAR(20) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_filter_rules_user_id ON filter_rules(user_id);
+
+CREATE TABLE users_hidden_posts (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+  rule_id BIGINT NOT NULL REFERENCES filter_rules(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (user_id, post_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS users_hidden_posts;
+DROP TABLE IF EXISTS filter_rules;
+DROP TABLE IF EXISTS post_categories;
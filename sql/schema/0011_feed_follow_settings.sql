@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE feed_follows
+  ADD COLUMN custom_name VARCHAR(255),
+  ADD COLUMN hide_from_home BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN sort_order VARCHAR(10) NOT NULL DEFAULT 'newest';
+
+-- +goose Down
+
+ALTER TABLE feed_follows
+  DROP COLUMN IF EXISTS sort_order,
+  DROP COLUMN IF EXISTS hide_from_home,
+  DROP COLUMN IF EXISTS custom_name;
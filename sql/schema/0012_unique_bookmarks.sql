@@ -0,0 +1,15 @@
+-- +goose Up
+
+DELETE FROM users_bookmarks ub
+USING users_bookmarks dup
+WHERE ub.user_id = dup.user_id
+  AND ub.post_id = dup.post_id
+  AND ub.id > dup.id;
+
+ALTER TABLE users_bookmarks
+  ADD CONSTRAINT users_bookmarks_user_id_post_id_key UNIQUE (user_id, post_id);
+
+-- +goose Down
+
+ALTER TABLE users_bookmarks
+  DROP CONSTRAINT IF EXISTS users_bookmarks_user_id_post_id_key;
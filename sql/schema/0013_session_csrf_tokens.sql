@@ -0,0 +1,12 @@
+-- +goose Up
+
+ALTER TABLE sessions ADD COLUMN csrf_token VARCHAR(64);
+
+UPDATE sessions
+SET csrf_token = replace(gen_random_uuid()::text || gen_random_uuid()::text, '-', '');
+
+ALTER TABLE sessions ALTER COLUMN csrf_token SET NOT NULL;
+
+-- +goose Down
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS csrf_token;
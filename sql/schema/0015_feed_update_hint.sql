@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- The publisher's polling hint (TTL or sy:updatePeriod) in seconds, kept for
+-- fetches answered with 304 Not Modified, which carry no feed body.
+ALTER TABLE feeds ADD COLUMN update_hint_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+
+ALTER TABLE feeds DROP COLUMN IF EXISTS update_hint_seconds;
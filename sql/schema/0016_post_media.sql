@@ -0,0 +1,13 @@
+-- +goose Up
+
+-- The first audio or video enclosure of each post, which post lists play
+-- inline.
+CREATE VIEW post_media AS
+SELECT DISTINCT ON (post_id) post_id, url, mime_type
+FROM post_enclosures
+WHERE mime_type LIKE 'audio/%' OR mime_type LIKE 'video/%'
+ORDER BY post_id, id;
+
+-- +goose Down
+
+DROP VIEW IF EXISTS post_media;
@@ -0,0 +1,41 @@
+document.addEventListener('DOMContentLoaded', function () {
+  const csrfMeta = document.querySelector('meta[name="csrf-token"]');
+  if (!csrfMeta) {
+    return;
+  }
+  const csrfToken = csrfMeta.content;
+
+  document.addEventListener('click', async function (event) {
+    const button = event.target.closest('.bookmark-btn, .unbookmark-btn');
+    if (!button) {
+      return;
+    }
+    const postId = button.getAttribute('data-post-id');
+    const bookmarked = button.classList.contains('unbookmark-btn');
+
+    try {
+      const response = await fetch(`/v1/posts/bookmarks/${postId}`, {
+        method: bookmarked ? 'DELETE' : 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'X-CSRF-Token': csrfToken,
+        }
+      });
+
+      if (!response.ok) {
+        alert(bookmarked ? 'Failed to remove bookmark' : 'Failed to bookmark post');
+        return;
+      }
+      // Lists of bookmarks drop the post instead of offering to bookmark it again.
+      if (bookmarked && button.closest('[data-remove-unbookmarked]')) {
+        button.closest('li').remove();
+        return;
+      }
+      button.textContent = bookmarked ? 'Bookmark' : 'Unbookmark';
+      button.className = bookmarked ? 'bookmark-btn' : 'unbookmark-btn';
+    } catch (error) {
+      console.error('Error:', error);
+      alert(bookmarked ? 'Failed to remove bookmark' : 'Failed to bookmark post');
+    }
+  });
+});